@@ -1,8 +1,11 @@
 package seeds
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"minter-sentinel/config"
+	"minter-sentinel/services/keystore"
 	"minter-sentinel/services/minter/node"
 	"syscall"
 
@@ -15,7 +18,8 @@ type Command struct {
 	log    *logrus.Logger
 	config *config.Config
 
-	minter *node.Service
+	minter   *node.Service
+	keystore string
 }
 
 func New(log *logrus.Logger, config *config.Config) *Command {
@@ -26,10 +30,21 @@ func New(log *logrus.Logger, config *config.Config) *Command {
 }
 
 func (cmd *Command) Command() *cli.Command {
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:     "keystore",
+			Required: false,
+			Usage:    "Write seeds to encrypted keystore `FILE` instead of printing them",
+		},
+	}
+
 	return &cli.Command{
 		Name:  "seeds",
 		Usage: "Get seed(s) of wallet(s)",
+		Flags: flags,
 		Action: func(ctx *cli.Context) error {
+			cmd.keystore = ctx.String("keystore")
+
 			if svc, err := node.New(cmd.config.Minter.NodeApi, cmd.config.Minter.Testnet, cmd.log); err != nil {
 				return err
 			} else {
@@ -69,6 +84,10 @@ func (cmd *Command) run() error {
 		seeds = append(seeds, wallet.Seed)
 	}
 
+	if len(cmd.keystore) > 0 {
+		return cmd.writeKeystore(seeds)
+	}
+
 	fmt.Println("Seeds:")
 
 	for _, seed := range seeds {
@@ -77,3 +96,55 @@ func (cmd *Command) run() error {
 
 	return nil
 }
+
+func (cmd *Command) writeKeystore(seeds []string) error {
+	passphrase, err := cmd.newPassphrase()
+
+	if err != nil {
+		return err
+	}
+
+	f, err := keystore.Encrypt(seeds, passphrase, keystore.DefaultParams)
+
+	if err != nil {
+		return err
+	}
+
+	if err := f.Save(cmd.keystore); err != nil {
+		return err
+	}
+
+	fmt.Printf("Keystore saved to %s\n", cmd.keystore)
+	fmt.Println("Addresses:")
+
+	for _, address := range f.Addresses {
+		fmt.Println(address)
+	}
+
+	return nil
+}
+
+// newPassphrase uses configured passphrase source or asks for a new passphrase twice.
+func (cmd *Command) newPassphrase() ([]byte, error) {
+	if len(cmd.config.Keystore.PassphraseFile) > 0 {
+		return keystore.Passphrase(cmd.config.Keystore.PassphraseFile, "")
+	}
+
+	passphrase, err := keystore.Prompt("New keystore passphrase (hidden): ")
+
+	if err != nil {
+		return nil, err
+	}
+
+	confirmation, err := keystore.Prompt("Repeat passphrase (hidden): ")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(passphrase, confirmation) {
+		return nil, errors.New("passphrases don't match")
+	}
+
+	return passphrase, nil
+}
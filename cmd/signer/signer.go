@@ -0,0 +1,90 @@
+package signer
+
+import (
+	"errors"
+	"minter-sentinel/config"
+	"minter-sentinel/services/keystore"
+	"minter-sentinel/services/signer"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+type Command struct {
+	log    *logrus.Logger
+	config *config.Config
+}
+
+func New(log *logrus.Logger, config *config.Config) *Command {
+	return &Command{
+		log:    log,
+		config: config,
+	}
+}
+
+func (cmd *Command) Command() *cli.Command {
+	return &cli.Command{
+		Name:  "signer",
+		Usage: "Start remote signer signing transactions to turn off masternode",
+		Action: func(ctx *cli.Context) error {
+			return cmd.run()
+		},
+	}
+}
+
+func (cmd *Command) run() error {
+	cfg := cmd.config.Signer
+
+	if len(cfg.Listen) == 0 {
+		return errors.New("`signer.listen` is not set in configuration file")
+	}
+
+	if len(cfg.Keystore) == 0 {
+		return errors.New("`signer.keystore` is not set in configuration file")
+	}
+
+	if len(cfg.PublicKeys) == 0 {
+		return errors.New("`signer.public_keys` is not set in configuration file")
+	}
+
+	f, err := keystore.Load(cfg.Keystore)
+
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := keystore.Passphrase(cmd.config.Keystore.PassphraseFile, cmd.config.Keystore.PassphraseEnv)
+
+	if err != nil {
+		return err
+	}
+
+	keys, err := f.Decrypt(passphrase)
+
+	if err != nil {
+		return err
+	}
+
+	cmd.log.WithField("path", cfg.Keystore).
+		WithField("addresses", f.Addresses).
+		WithField("public_keys", cfg.PublicKeys).
+		Println("Keystore unlocked")
+
+	policy := signer.NewPolicy(cmd.config.Minter.Testnet, cfg.PublicKeys)
+	policy.SetGas(cfg.GasCoins, cfg.MaxGasPrice)
+
+	server := signer.NewServer(keys, policy, cmd.log)
+
+	if strings.HasPrefix(cfg.Listen, "unix://") {
+		return server.ListenAndServe(cfg.Listen, nil)
+	}
+
+	tlsConfig, err := signer.ServerTLSConfig(cfg.TLS.Cert, cfg.TLS.Key, cfg.TLS.CA)
+
+	if err != nil {
+		return err
+	}
+
+	return server.ListenAndServe(cfg.Listen, tlsConfig)
+}
@@ -0,0 +1,99 @@
+package start
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"minter-sentinel/services/notifier"
+	"time"
+)
+
+const (
+	defaultBalanceInterval           = 60
+	defaultBalanceCommissionMultiple = 10
+)
+
+// monitorBalance checks periodically that control address can afford turn off transaction.
+func (w *watcher) monitorBalance(ctx context.Context) {
+	interval := w.cmd.config.Minter.Balance.Interval
+
+	if interval <= 0 {
+		interval = defaultBalanceInterval
+	}
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		w.checkBalance()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *watcher) checkBalance() {
+	c, err := w.cmd.minter.NewCandidateOff(w.validator.PublicKey, w.controlAddress)
+
+	if err != nil {
+		w.backgroundLogEntry().WithError(err).Warnln("Failed to check balance of control address")
+		return
+	}
+
+	if w.cmd.prometheus != nil {
+		w.cmd.prometheus.SetControlAddressBalance(w.validator.PublicKey, c.WalletAddress, c.GasCoin, pipToFloat(c.Balance))
+
+		if c.Commission != nil {
+			w.cmd.prometheus.SetTurnOffCommission(w.validator.PublicKey, c.GasCoin, pipToFloat(c.Commission))
+		}
+	}
+
+	if c.Commission == nil {
+		return
+	}
+
+	multiple := w.cmd.config.Minter.Balance.CommissionMultiple
+
+	if multiple <= 0 {
+		multiple = defaultBalanceCommissionMultiple
+	}
+
+	required := new(big.Int).Mul(c.Commission, big.NewInt(int64(multiple)))
+	low := c.Balance.Cmp(required) < 0
+
+	w.mu.Lock()
+	changed := w.balanceWarned != low
+	w.balanceWarned = low
+	w.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	entry := w.backgroundLogEntry().
+		WithField("control_address", c.WalletAddress).
+		WithField("gas_coin", c.GasCoin).
+		WithField("balance", c.Balance).
+		WithField("commission", c.Commission)
+
+	if low {
+		entry.Warnf("Balance of control address is lower than %d turn off commissions", multiple)
+
+		w.notify(notifier.Warning, fmt.Sprintf("⚠️ Balance of %s in coin %d is %s pip, lower than %d turn off commissions (%s pip)", c.WalletAddress, c.GasCoin, c.Balance, multiple, required))
+
+		return
+	}
+
+	entry.Println("Balance of control address is enough again")
+
+	w.notify(notifier.Info, fmt.Sprintf("✅ Balance of %s in coin %d is %s pip", c.WalletAddress, c.GasCoin, c.Balance))
+}
+
+func pipToFloat(pip *big.Int) float64 {
+	value, _ := new(big.Float).Quo(new(big.Float).SetInt(pip), big.NewFloat(1e18)).Float64()
+
+	return value
+}
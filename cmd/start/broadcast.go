@@ -0,0 +1,213 @@
+package start
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"minter-sentinel/config"
+	"minter-sentinel/services/minter/node"
+	"time"
+)
+
+var CandidateAlreadyOff = errors.New("candidate is already off")
+
+type broadcastStep struct {
+	name  string
+	retry config.Retry
+	send  func() (string, *node.SendTransactionResponse, error)
+}
+
+type rejectedError struct {
+	endpoint string
+	err      *node.Error
+}
+
+func (e *rejectedError) Error() string {
+	return fmt.Sprintf("%s rejected transaction: [%d] %s", e.endpoint, e.err.Code, e.err.Message)
+}
+
+// broadcastTurnOff goes through the fallback chain until transaction is accepted by a node:
+// pre-signed or freshly signed transaction, transaction signed with refreshed nonce, configured transaction_off
+// and then every transaction tried so far through each node_api endpoint.
+func (w *watcher) broadcastTurnOff(ctx context.Context) (string, *node.SendTransactionResponse, error) {
+	var tried []string
+
+	send := func(tx string) (string, *node.SendTransactionResponse, error) {
+		tried = appendUnique(tried, tx)
+
+		resp, err := w.cmd.minter.SendTransaction(tx)
+
+		if err != nil {
+			return tx, nil, err
+		}
+
+		if resp.Error != nil {
+			return tx, nil, &rejectedError{endpoint: resp.Endpoint, err: resp.Error}
+		}
+
+		return tx, resp, nil
+	}
+
+	cfg := w.cmd.config.Minter.Broadcast
+
+	var steps []broadcastStep
+
+	if w.canSign() {
+		steps = append(steps, broadcastStep{
+			name:  "signed",
+			retry: cfg.Signed,
+			send: func() (string, *node.SendTransactionResponse, error) {
+				if presigned := w.takePresigned(); presigned != nil {
+					w.newLogEntry(w.lastBlock).
+						WithField("nonce", presigned.nonce).
+						WithField("age", time.Since(presigned.signedAt)).
+						Println("Using pre-signed transaction")
+
+					return send(presigned.tx)
+				}
+
+				tx, err := w.generateTransactionOff()
+
+				if err != nil {
+					return "", nil, fmt.Errorf("failed to generate transaction: %w", err)
+				}
+
+				return send(tx)
+			},
+		}, broadcastStep{
+			name:  "resigned",
+			retry: cfg.Resigned,
+			send: func() (string, *node.SendTransactionResponse, error) {
+				tx, err := w.generateTransactionOff()
+
+				if err != nil {
+					return "", nil, fmt.Errorf("failed to generate transaction: %w", err)
+				}
+
+				return send(tx)
+			},
+		})
+	}
+
+	if len(w.validator.TransactionOff) > 0 {
+		steps = append(steps, broadcastStep{
+			name:  "transaction_off",
+			retry: cfg.TransactionOff,
+			send: func() (string, *node.SendTransactionResponse, error) {
+				return send(w.validator.TransactionOff)
+			},
+		})
+	}
+
+	steps = append(steps, broadcastStep{
+		name:  "each_node",
+		retry: cfg.EachNode,
+		send: func() (string, *node.SendTransactionResponse, error) {
+			var lastErr error = errors.New("no transaction to broadcast")
+
+			for _, tx := range tried {
+				for _, result := range w.cmd.minter.BroadcastTransaction(tx) {
+					if result.Err != nil {
+						lastErr = result.Err
+						continue
+					}
+
+					if result.Response.Error != nil {
+						lastErr = &rejectedError{endpoint: result.Endpoint, err: result.Response.Error}
+
+						if w.isAlreadyOff(lastErr) {
+							return tx, nil, lastErr
+						}
+
+						continue
+					}
+
+					return tx, result.Response, nil
+				}
+			}
+
+			return "", nil, lastErr
+		},
+	})
+
+	var lastErr error
+
+	for _, step := range steps {
+		attempts := step.retry.Attempts
+
+		if attempts <= 0 {
+			attempts = 1
+		}
+
+		for attempt := 1; attempt <= attempts; attempt++ {
+			tx, resp, err := step.send()
+
+			if err == nil {
+				w.newLogEntry(w.lastBlock).
+					WithField("step", step.name).
+					WithField("attempt", attempt).
+					WithField("hash", resp.Hash).
+					WithField("node", resp.Endpoint).
+					Println("Turn off transaction accepted")
+
+				return tx, resp, nil
+			}
+
+			if w.isAlreadyOff(err) {
+				return "", nil, CandidateAlreadyOff
+			}
+
+			lastErr = err
+
+			w.newLogEntry(w.lastBlock).
+				WithField("step", step.name).
+				WithField("attempt", attempt).
+				WithError(err).
+				Warnln("Failed to broadcast turn off transaction")
+
+			if attempt < attempts {
+				select {
+				case <-ctx.Done():
+					return "", nil, ctx.Err()
+				case <-time.After(time.Duration(step.retry.Delay) * time.Second):
+				}
+			}
+		}
+	}
+
+	return "", nil, fmt.Errorf("all broadcast attempts failed: %w", lastErr)
+}
+
+// isAlreadyOff checks if rejection means that candidate is already off: either by configured error codes,
+// or by asking Node API for the candidate status.
+func (w *watcher) isAlreadyOff(err error) bool {
+	rejected, ok := err.(*rejectedError)
+
+	if !ok {
+		return false
+	}
+
+	for _, code := range w.cmd.config.Minter.Broadcast.AlreadyOffCodes {
+		if rejected.err.Code == code {
+			return true
+		}
+	}
+
+	return w.isCandidateOffline()
+}
+
+func (w *watcher) isCandidateOffline() bool {
+	candidate, err := w.cmd.minter.GetCandidate(w.validator.PublicKey)
+
+	return err == nil && candidate.Status != node.CandidateStatusOnline
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+
+	return append(list, value)
+}
@@ -0,0 +1,114 @@
+package start
+
+import (
+	"context"
+	"errors"
+	"minter-sentinel/config"
+	"minter-sentinel/services/minter/node"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatcher_BroadcastTurnOff(t *testing.T) {
+	const presignedTx = "0xpresigned"
+	const transactionOff = "0xoff"
+
+	tests := []struct {
+		name        string
+		rejectSends int
+		rejectCode  int
+		offline     bool
+		accepted    string
+		sends       int
+		err         error
+	}{
+		{"presigned", 0, 0, false, presignedTx, 1, nil},
+		{"resigned", 1, 0, false, "", 2, nil},
+		{"transaction_off", 2, 0, false, transactionOff, 3, nil},
+		{"each node", 3, 0, false, presignedTx, 4, nil},
+		{"all failed", 100, 0, false, "", 6, errors.New("all broadcast attempts failed")},
+		{"already off code", 100, 107, false, "", 1, CandidateAlreadyOff},
+		{"already offline", 100, 0, true, "", 1, CandidateAlreadyOff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := newTestKeys(t, 1)
+
+			n := newFakeNode(t)
+			n.candidate.ControlAddress = keys[0].Address
+			n.rejectSends = tt.rejectSends
+
+			if tt.rejectCode > 0 {
+				n.rejectCode = tt.rejectCode
+			}
+
+			if tt.offline {
+				n.candidate.Status = node.CandidateStatusOffline
+			}
+
+			w, _ := newTestWatcher(t, n, config.Minter{Broadcast: config.Broadcast{AlreadyOffCodes: []int{107}}})
+			w.keys = keys
+			w.validator.TransactionOff = transactionOff
+			w.presigned = &presigned{tx: presignedTx, signedAt: time.Now()}
+
+			tx, resp, err := w.broadcastTurnOff(context.Background())
+
+			switch {
+			case tt.err == CandidateAlreadyOff:
+				if err != CandidateAlreadyOff {
+					t.Fatalf("expected candidate already off, got %v", err)
+				}
+			case tt.err != nil:
+				if err == nil || !strings.Contains(err.Error(), tt.err.Error()) {
+					t.Fatalf("expected %q error, got %v", tt.err, err)
+				}
+			case err != nil:
+				t.Fatalf("unexpected error: %s", err)
+			case resp == nil || len(resp.Hash) == 0:
+				t.Fatalf("wrong response: %+v", resp)
+			}
+
+			sent := n.sentTransactions()
+
+			if len(sent) != tt.sends {
+				t.Fatalf("wrong number of sends: expected %d, got %d", tt.sends, len(sent))
+			}
+
+			if tt.err != nil {
+				return
+			}
+
+			if len(tt.accepted) > 0 && tx != tt.accepted {
+				t.Fatalf("wrong accepted transaction: expected %s, got %s", tt.accepted, tx)
+			}
+
+			// re-signed transaction is built by the watcher itself
+			if len(tt.accepted) == 0 && (tx == presignedTx || !strings.HasPrefix(tx, "0x")) {
+				t.Fatalf("expected freshly signed transaction, got %s", tx)
+			}
+		})
+	}
+}
+
+func TestWatcher_BroadcastTurnOff_Cancel(t *testing.T) {
+	n := newFakeNode(t)
+	n.rejectSends = 100
+
+	w, _ := newTestWatcher(t, n, config.Minter{Broadcast: config.Broadcast{TransactionOff: config.Retry{Attempts: 3, Delay: 60}}})
+	w.validator.TransactionOff = "0xoff"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+
+	if _, _, err := w.broadcastTurnOff(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context error, got %v", err)
+	}
+
+	if time.Since(started) > 5*time.Second {
+		t.Fatalf("broadcast should stop waiting for retry when context is done")
+	}
+}
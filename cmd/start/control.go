@@ -0,0 +1,106 @@
+package start
+
+import "minter-sentinel/services/control"
+
+func (cmd *Command) Validators() []control.Validator {
+	validators := make([]control.Validator, 0, len(cmd.watchers))
+
+	for _, w := range cmd.watchers {
+		validators = append(validators, w.snapshot())
+	}
+
+	return validators
+}
+
+func (cmd *Command) Candidate(publicKey string) (*control.Candidate, error) {
+	if _, err := cmd.watcher(publicKey); err != nil {
+		return nil, err
+	}
+
+	c, err := cmd.minter.GetCandidate(publicKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &control.Candidate{
+		Status:      c.Status,
+		Validator:   c.Validator,
+		JailedUntil: c.JailedUntil,
+	}, nil
+}
+
+func (cmd *Command) Pause(publicKey string) error {
+	w, err := cmd.watcher(publicKey)
+
+	if err != nil {
+		return err
+	}
+
+	w.setPaused(true)
+	w.backgroundLogEntry().Warnln("Automatic turn off paused")
+
+	return nil
+}
+
+func (cmd *Command) Resume(publicKey string) error {
+	w, err := cmd.watcher(publicKey)
+
+	if err != nil {
+		return err
+	}
+
+	w.setPaused(false)
+	w.backgroundLogEntry().Println("Automatic turn off resumed")
+
+	return nil
+}
+
+func (cmd *Command) TurnOff(publicKey string) error {
+	w, err := cmd.watcher(publicKey)
+
+	if err != nil {
+		return err
+	}
+
+	return w.requestTurnOff()
+}
+
+func (cmd *Command) watcher(publicKey string) (*watcher, error) {
+	for _, w := range cmd.watchers {
+		if w.validator.PublicKey == publicKey {
+			return w, nil
+		}
+	}
+
+	return nil, control.ValidatorNotFound
+}
+
+func (cmd *Command) Reset(publicKey string) error {
+	w, err := cmd.watcher(publicKey)
+
+	if err != nil {
+		return err
+	}
+
+	return w.requestReset()
+}
+
+func (cmd *Command) Nodes() []control.Node {
+	active := cmd.minter.Endpoint()
+	health := cmd.minter.Health()
+
+	nodes := make([]control.Node, 0, len(health))
+
+	for _, h := range health {
+		nodes = append(nodes, control.Node{
+			URL:       h.URL,
+			Up:        !h.Open,
+			Height:    h.Height,
+			Active:    h.URL == active,
+			LastError: h.LastError,
+		})
+	}
+
+	return nodes
+}
@@ -0,0 +1,49 @@
+package start
+
+import (
+	"minter-sentinel/config"
+	"testing"
+)
+
+// TestCommand_PauseResume is meant to be run with -race: pause and resume come from Telegram and API goroutines
+// while the watcher loop records missed blocks.
+func TestCommand_PauseResume(t *testing.T) {
+	w, _ := newTestWatcher(t, newFakeNode(t), config.Minter{})
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 100; i++ {
+			w.mu.Lock()
+			w.missedBlocks = append(w.missedBlocks, i)
+			w.lastBlock = i
+			w.mu.Unlock()
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		if err := w.cmd.Pause(testPublicKey); err != nil {
+			t.Fatalf("failed to pause: %s", err)
+		}
+
+		if !w.isPaused() {
+			t.Fatalf("watcher should be paused")
+		}
+
+		if err := w.cmd.Resume(testPublicKey); err != nil {
+			t.Fatalf("failed to resume: %s", err)
+		}
+	}
+
+	<-done
+
+	if w.isPaused() {
+		t.Fatalf("watcher should be resumed")
+	}
+
+	if err := w.cmd.Pause("Mp00"); err == nil {
+		t.Fatalf("expected error for unknown validator")
+	}
+}
@@ -0,0 +1,128 @@
+package start
+
+import (
+	"fmt"
+	"minter-sentinel/services/notifier"
+	"time"
+)
+
+const (
+	errorPolicyRetry   = "retry"
+	errorPolicyAlert   = "alert"
+	errorPolicyTurnOff = "turn_off"
+
+	defaultMaxBackoff = 60
+	defaultAlertAfter = 3
+)
+
+type checkErrors struct {
+	consecutive int
+	firstAt     time.Time
+	retryAt     time.Time
+	alerted     bool
+}
+
+// handleCheckError applies the configured error policy to a failed block check and reports whether masternode should be turned off.
+func (w *watcher) handleCheckError(height int, err error) bool {
+	policy := w.cmd.config.Minter.ErrorPolicy
+	now := time.Now()
+
+	if w.errors.consecutive == 0 {
+		w.errors.firstAt = now
+	}
+
+	w.errors.consecutive++
+
+	backoff := w.errorBackoff()
+	outage := now.Sub(w.errors.firstAt)
+
+	w.errors.retryAt = now.Add(backoff)
+
+	alertAfter := policy.AlertAfter
+
+	if alertAfter <= 0 {
+		alertAfter = defaultAlertAfter
+	}
+
+	decision := errorPolicyRetry
+
+	if policy.TurnOffAfter > 0 && outage >= time.Duration(policy.TurnOffAfter)*time.Second && !w.isPaused() {
+		decision = errorPolicyTurnOff
+	} else if w.errors.consecutive >= alertAfter && !w.errors.alerted {
+		decision = errorPolicyAlert
+	}
+
+	entry := w.newLogEntry(height).
+		WithError(err).
+		WithField("policy", decision).
+		WithField("errors", w.errors.consecutive).
+		WithField("outage", outage.Round(time.Second)).
+		WithField("retry_in", backoff)
+
+	switch decision {
+	case errorPolicyTurnOff:
+		entry.Errorln("Failed to detect if block is signed. Node API outage is too long")
+
+		w.notify(notifier.Critical, fmt.Sprintf("🚨 Failed to detect if block is signed for %s: %s", outage.Round(time.Second), err))
+
+		return true
+	case errorPolicyAlert:
+		entry.Errorln("Failed to detect if block is signed")
+
+		w.errors.alerted = true
+
+		w.notify(notifier.Warning, fmt.Sprintf("⚠️ Failed to detect if block is signed %d times in a row: %s", w.errors.consecutive, err))
+	default:
+		entry.Warnln("Failed to detect if block is signed")
+	}
+
+	return false
+}
+
+func (w *watcher) recoverFromErrors(height int) {
+	if w.errors.consecutive == 0 {
+		return
+	}
+
+	outage := time.Since(w.errors.firstAt).Round(time.Second)
+
+	w.newLogEntry(height).
+		WithField("errors", w.errors.consecutive).
+		WithField("outage", outage).
+		Println("Node API recovered")
+
+	if w.errors.alerted {
+		w.notify(notifier.Info, fmt.Sprintf("✅ Node API recovered after %s", outage))
+	}
+
+	w.errors = checkErrors{}
+}
+
+// errorBackoff doubles the delay before the next check on every consecutive error.
+func (w *watcher) errorBackoff() time.Duration {
+	policy := w.cmd.config.Minter.ErrorPolicy
+
+	backoff := policy.Backoff
+
+	if backoff <= 0 {
+		backoff = w.cmd.config.Minter.Sleep
+	}
+
+	maxBackoff := policy.MaxBackoff
+
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
+	delay := backoff
+
+	for i := 1; i < w.errors.consecutive && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	return time.Duration(delay) * time.Second
+}
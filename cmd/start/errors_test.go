@@ -0,0 +1,103 @@
+package start
+
+import (
+	"errors"
+	"minter-sentinel/config"
+	"testing"
+	"time"
+)
+
+func TestWatcher_ErrorBackoff(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      config.ErrorPolicy
+		consecutive int
+		backoff     time.Duration
+	}{
+		{"first error", config.ErrorPolicy{Backoff: 2, MaxBackoff: 10}, 1, 2 * time.Second},
+		{"doubled", config.ErrorPolicy{Backoff: 2, MaxBackoff: 10}, 3, 8 * time.Second},
+		{"capped", config.ErrorPolicy{Backoff: 2, MaxBackoff: 10}, 4, 10 * time.Second},
+		{"long streak", config.ErrorPolicy{Backoff: 2, MaxBackoff: 10}, 100, 10 * time.Second},
+		{"sleep by default", config.ErrorPolicy{}, 1, 5 * time.Second},
+		{"default cap", config.ErrorPolicy{}, 10, defaultMaxBackoff * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &watcher{cmd: &Command{config: &config.Config{Minter: config.Minter{Sleep: 5, ErrorPolicy: tt.policy}}}}
+			w.errors.consecutive = tt.consecutive
+
+			if backoff := w.errorBackoff(); backoff != tt.backoff {
+				t.Fatalf("wrong backoff: expected %s, got %s", tt.backoff, backoff)
+			}
+		})
+	}
+}
+
+func TestWatcher_HandleCheckError(t *testing.T) {
+	policy := config.ErrorPolicy{Backoff: 1, AlertAfter: 2, TurnOffAfter: 60}
+
+	steps := []struct {
+		name    string
+		outage  time.Duration
+		paused  bool
+		turnOff bool
+		alerts  int
+	}{
+		{"first error is retried", 0, false, false, 0},
+		{"alert after streak", 0, false, false, 1},
+		{"alert once per streak", 0, false, false, 1},
+		{"outage too long while paused", 2 * time.Minute, true, false, 1},
+		{"outage too long", 2 * time.Minute, false, true, 1},
+	}
+
+	w, r := newTestWatcher(t, newFakeNode(t), config.Minter{ErrorPolicy: policy})
+
+	countAlerts := func() int {
+		w.cmd.wg.Wait()
+
+		alerts := 0
+
+		for _, text := range r.texts() {
+			if text == "⚠️ Failed to detect if block is signed 2 times in a row: node is down" {
+				alerts++
+			}
+		}
+
+		return alerts
+	}
+
+	for _, step := range steps {
+		if step.outage > 0 {
+			w.errors.firstAt = time.Now().Add(-step.outage)
+		}
+
+		w.setPaused(step.paused)
+
+		if turnOff := w.handleCheckError(100, errors.New("node is down")); turnOff != step.turnOff {
+			t.Fatalf("%s: expected turn off %v, got %v", step.name, step.turnOff, turnOff)
+		}
+
+		if alerts := countAlerts(); alerts != step.alerts {
+			t.Fatalf("%s: expected %d alerts, got %d: %v", step.name, step.alerts, alerts, r.texts())
+		}
+
+		if w.errors.retryAt.IsZero() {
+			t.Fatalf("%s: next check should be delayed", step.name)
+		}
+	}
+
+	w.recoverFromErrors(101)
+	w.cmd.wg.Wait()
+
+	if w.errors.consecutive != 0 || !r.contains("Node API recovered") {
+		t.Fatalf("errors should be reset and recovery notified after alert: %v", r.texts())
+	}
+
+	w.handleCheckError(102, errors.New("node is down"))
+	w.handleCheckError(103, errors.New("node is down"))
+
+	if alerts := countAlerts(); alerts != 2 {
+		t.Fatalf("new streak should be alerted again: %v", r.texts())
+	}
+}
@@ -0,0 +1,392 @@
+package start
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"minter-sentinel/config"
+	"minter-sentinel/services/notifier"
+	"minter-sentinel/services/policy"
+	"minter-sentinel/services/webhook"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+const (
+	actionLog     = "log"
+	actionNotify  = "notify"
+	actionPage    = "page"
+	actionHook    = "hook"
+	actionTurnOff = "turn_off"
+
+	hookTimeout = 30 * time.Second
+)
+
+type rung struct {
+	name    string
+	missed  int
+	rate    float64
+	window  int
+	policy  bool
+	actions []action
+}
+
+type action struct {
+	kind     string
+	severity notifier.Severity
+	webhook  *webhook.Service
+	command  []string
+}
+
+// newLadder builds escalation ladder of validator. Without configured rungs every missed block is a warning
+// and masternode is turned off when missed blocks policy is exceeded.
+func newLadder(v config.Validator) ([]rung, error) {
+	if len(v.Escalation) == 0 {
+		return []rung{
+			{
+				missed: 1,
+				window: defaultLadderWindow(v),
+				actions: []action{
+					{kind: actionLog, severity: notifier.Warning},
+					{kind: actionNotify, severity: notifier.Warning},
+				},
+			},
+			{
+				policy: true,
+				actions: []action{
+					{kind: actionLog, severity: notifier.Critical},
+					{kind: actionNotify, severity: notifier.Critical},
+					{kind: actionTurnOff},
+				},
+			},
+		}, nil
+	}
+
+	ladder := make([]rung, 0, len(v.Escalation))
+
+	for i, e := range v.Escalation {
+		r := rung{name: e.Name, missed: e.Missed, rate: e.Rate, window: e.Window, policy: e.Policy}
+
+		if r.name == "" {
+			r.name = strconv.Itoa(i + 1)
+		}
+
+		if r.missed <= 0 && r.rate <= 0 && !r.policy {
+			return nil, fmt.Errorf("escalation %s: missed, rate or policy must be set", r.name)
+		}
+
+		if r.window == 0 {
+			r.window = v.MissedBlockRemoveAfter
+		}
+
+		if !r.policy && r.window <= 0 {
+			return nil, fmt.Errorf("escalation %s: window must be positive, set window or missed_block_remove_after", r.name)
+		}
+
+		if !r.policy && r.window > v.MissedBlockRemoveAfter {
+			return nil, fmt.Errorf("escalation %s: window can't be larger than missed_block_remove_after", r.name)
+		}
+
+		if len(e.Actions) == 0 {
+			return nil, fmt.Errorf("escalation %s: no actions", r.name)
+		}
+
+		for _, a := range e.Actions {
+			act, err := newAction(a)
+
+			if err != nil {
+				return nil, fmt.Errorf("escalation %s: %w", r.name, err)
+			}
+
+			r.actions = append(r.actions, act)
+		}
+
+		ladder = append(ladder, r)
+	}
+
+	return ladder, nil
+}
+
+// defaultLadderWindow counts at least the current block, so every missed block is a warning even without missed_block_remove_after.
+func defaultLadderWindow(v config.Validator) int {
+	if v.MissedBlockRemoveAfter > 0 {
+		return v.MissedBlockRemoveAfter
+	}
+
+	return 1
+}
+
+func newAction(a config.EscalationAction) (action, error) {
+	act := action{kind: a.Type, severity: notifier.Warning}
+
+	if a.Severity != "" {
+		severity, err := notifier.ParseSeverity(a.Severity)
+
+		if err != nil {
+			return act, err
+		}
+
+		act.severity = severity
+	}
+
+	switch a.Type {
+	case actionLog, actionNotify, actionPage, actionTurnOff:
+	case actionHook:
+		if len(a.URL) == 0 && len(a.Command) == 0 {
+			return act, errors.New("hook requires url or command")
+		}
+
+		if len(a.URL) > 0 {
+			h, err := webhook.New(a.URL, a.Secret)
+
+			if err != nil {
+				return act, err
+			}
+
+			act.webhook = h
+		}
+
+		act.command = a.Command
+	default:
+		return act, fmt.Errorf("unknown action: %s", a.Type)
+	}
+
+	return act, nil
+}
+
+// matches reports whether the rung is reached with the given missed blocks at the given height.
+func (r rung) matches(missedBlocks []int, height int, verdict policy.Verdict) bool {
+	if r.policy {
+		return verdict.Exceeded
+	}
+
+	missed := r.count(missedBlocks, height)
+
+	if r.missed > 0 && missed < r.missed {
+		return false
+	}
+
+	if r.rate > 0 && float64(missed)*100/float64(r.window) < r.rate {
+		return false
+	}
+
+	return true
+}
+
+func (r rung) count(missedBlocks []int, height int) int {
+	missed := 0
+
+	for _, h := range missedBlocks {
+		if height-h < r.window {
+			missed++
+		}
+	}
+
+	return missed
+}
+
+// highestRung returns index of the highest reached rung of the ladder, or -1 if none is reached.
+func highestRung(ladder []rung, missedBlocks []int, height int, verdict policy.Verdict) int {
+	reached := -1
+
+	for i := range ladder {
+		if ladder[i].matches(missedBlocks, height, verdict) {
+			reached = i
+		}
+	}
+
+	return reached
+}
+
+// updateEscalation remembers the highest reached rung and reports whether it's higher than before.
+// The level drops as missed blocks leave the window, so page and hook are fired again on the next escalation.
+func (w *watcher) updateEscalation(height int) (reached int, escalated bool) {
+	w.mu.RLock()
+	missedBlocks := append([]int(nil), w.missedBlocks...)
+	w.mu.RUnlock()
+
+	reached = highestRung(w.ladder, missedBlocks, height, w.verdict)
+	escalated = reached > w.escalation
+	w.escalation = reached
+
+	return reached, escalated
+}
+
+// escalate runs actions of the highest reached rung of escalation ladder and reports whether masternode should be turned off.
+// Page and hook actions are run only when the rung is reached, not on every following missed block.
+func (w *watcher) escalate(height int) bool {
+	index, escalated := w.updateEscalation(height)
+
+	if index < 0 {
+		w.newLogEntry(height).Println("Block missed")
+		return false
+	}
+
+	w.mu.RLock()
+	missedBlocks := append([]int(nil), w.missedBlocks...)
+	w.mu.RUnlock()
+
+	reached := &w.ladder[index]
+
+	text := w.escalationText(reached, missedBlocks, height)
+	missed := w.rungMissed(reached, missedBlocks, height)
+	turnOff := false
+
+	for _, a := range reached.actions {
+		switch a.kind {
+		case actionLog:
+			entry := w.newLogEntry(height).WithField("escalation", reached.name).WithField("missed", missed)
+
+			switch a.severity {
+			case notifier.Critical:
+				entry.Errorln("Block missed")
+			case notifier.Warning:
+				entry.Warnln("Block missed")
+			default:
+				entry.Println("Block missed")
+			}
+		case actionNotify:
+			w.notify(a.severity, fmt.Sprintf("%s %s", severityIcon(a.severity), text))
+		case actionPage:
+			if escalated {
+				w.page(fmt.Sprintf("📟 %s", text))
+			}
+		case actionHook:
+			if escalated {
+				w.runHook(a, reached, missed, height, text)
+			}
+		case actionTurnOff:
+			if w.isPaused() {
+				w.newLogEntry(height).Errorln("Missed blocks threshold exceeded. Automatic turn off is paused")
+
+				w.notify(notifier.Critical, fmt.Sprintf("🚨 %s. Automatic turn off is paused", text))
+
+				continue
+			}
+
+			turnOff = true
+		}
+	}
+
+	if turnOff {
+		w.newLogEntry(height).WithField("escalation", reached.name).Errorln("Missed blocks threshold exceeded")
+	}
+
+	return turnOff
+}
+
+// escalationText describes missed block relative to the rung turning off masternode, e.g. "Block 10 missed [2/4]".
+// Without configured rungs it's relative to missed blocks policy.
+func (w *watcher) escalationText(reached *rung, missedBlocks []int, height int) string {
+	if len(w.validator.Escalation) == 0 {
+		return fmt.Sprintf("Block %d missed [%d/%d]", height, w.verdict.Missed, w.verdict.Limit)
+	}
+
+	limit := reached.window
+
+	for _, r := range w.ladder {
+		if !r.turnsOff() {
+			continue
+		}
+
+		if r.policy {
+			limit = w.verdict.Limit
+		} else if r.missed > 0 {
+			limit = r.missed
+		}
+
+		break
+	}
+
+	return fmt.Sprintf("Block %d missed [%d/%d] (%s)", height, w.rungMissed(reached, missedBlocks, height), limit, reached.name)
+}
+
+// rungMissed returns number of missed blocks counted by the rung, or by missed blocks policy for policy rung.
+func (w *watcher) rungMissed(r *rung, missedBlocks []int, height int) int {
+	if r.policy {
+		return w.verdict.Missed
+	}
+
+	return r.count(missedBlocks, height)
+}
+
+func (r rung) turnsOff() bool {
+	for _, a := range r.actions {
+		if a.kind == actionTurnOff {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *watcher) page(text string) {
+	if len(w.cmd.config.Minter.Validators) > 1 {
+		text = fmt.Sprintf("[%s] %s", shortPublicKey(w.validator.PublicKey), text)
+	}
+
+	w.cmd.notify(w.notifier, notifier.Message{
+		Severity:  notifier.Critical,
+		Text:      text,
+		PublicKey: w.validator.PublicKey,
+		Urgent:    true,
+	})
+}
+
+// runHook posts the escalation to hook URL and runs hook command in background.
+func (w *watcher) runHook(a action, reached *rung, missed int, height int, text string) {
+	entry := w.newLogEntry(height).WithField("escalation", reached.name)
+
+	w.cmd.wg.Add(1)
+
+	go func() {
+		defer w.cmd.wg.Done()
+
+		if a.webhook != nil {
+			err := a.webhook.Notify(notifier.Message{
+				Severity:  a.severity,
+				Text:      text,
+				PublicKey: w.validator.PublicKey,
+				Time:      time.Now(),
+			})
+
+			if err != nil {
+				entry.WithError(err).Errorln("Failed to call escalation webhook")
+			}
+		}
+
+		if len(a.command) == 0 {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+		defer cancel()
+
+		c := exec.CommandContext(ctx, a.command[0], a.command[1:]...)
+		c.Env = append(os.Environ(),
+			"SENTINEL_PUBLIC_KEY="+w.validator.PublicKey,
+			"SENTINEL_ESCALATION="+reached.name,
+			"SENTINEL_SEVERITY="+a.severity.String(),
+			"SENTINEL_HEIGHT="+strconv.Itoa(height),
+			"SENTINEL_MISSED="+strconv.Itoa(missed),
+			"SENTINEL_MESSAGE="+text,
+		)
+
+		if output, err := c.CombinedOutput(); err != nil {
+			entry.WithError(err).WithField("output", string(output)).Errorln("Escalation command failed")
+		}
+	}()
+}
+
+func severityIcon(severity notifier.Severity) string {
+	switch severity {
+	case notifier.Critical:
+		return "🚨"
+	case notifier.Warning:
+		return "⚠️"
+	default:
+		return "ℹ️"
+	}
+}
@@ -0,0 +1,145 @@
+package start
+
+import (
+	"minter-sentinel/config"
+	"minter-sentinel/services/policy"
+	"testing"
+)
+
+func TestRung_Matches(t *testing.T) {
+	tests := []struct {
+		name    string
+		rung    rung
+		missed  []int
+		height  int
+		verdict policy.Verdict
+		count   int
+		matches bool
+	}{
+		{"missed reached", rung{missed: 2, window: 10}, []int{95, 100}, 100, policy.Verdict{}, 2, true},
+		{"missed not reached", rung{missed: 3, window: 10}, []int{95, 100}, 100, policy.Verdict{}, 2, false},
+		{"missed out of window", rung{missed: 2, window: 5}, []int{95, 100}, 100, policy.Verdict{}, 1, false},
+		{"rate reached", rung{rate: 20, window: 10}, []int{95, 100}, 100, policy.Verdict{}, 2, true},
+		{"rate not reached", rung{rate: 30, window: 10}, []int{95, 100}, 100, policy.Verdict{}, 2, false},
+		{"missed and rate", rung{missed: 2, rate: 30, window: 10}, []int{95, 100}, 100, policy.Verdict{}, 2, false},
+		{"policy exceeded", rung{policy: true}, nil, 100, policy.Verdict{Exceeded: true}, 0, true},
+		{"policy not exceeded", rung{policy: true}, []int{99, 100}, 100, policy.Verdict{Missed: 2}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.rung.policy {
+				if count := tt.rung.count(tt.missed, tt.height); count != tt.count {
+					t.Fatalf("wrong count: expected %d, got %d", tt.count, count)
+				}
+			}
+
+			if matches := tt.rung.matches(tt.missed, tt.height, tt.verdict); matches != tt.matches {
+				t.Fatalf("wrong match: expected %v, got %v", tt.matches, matches)
+			}
+		})
+	}
+}
+
+func TestHighestRung(t *testing.T) {
+	ladder := []rung{
+		{missed: 1, window: 24},
+		{missed: 3, window: 24},
+		{policy: true},
+	}
+
+	tests := []struct {
+		name    string
+		missed  []int
+		verdict policy.Verdict
+		reached int
+	}{
+		{"none", nil, policy.Verdict{}, -1},
+		{"lowest", []int{100}, policy.Verdict{}, 0},
+		{"middle", []int{98, 99, 100}, policy.Verdict{}, 1},
+		{"policy", []int{100}, policy.Verdict{Exceeded: true}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if reached := highestRung(ladder, tt.missed, 100, tt.verdict); reached != tt.reached {
+				t.Fatalf("wrong rung: expected %d, got %d", tt.reached, reached)
+			}
+		})
+	}
+}
+
+func TestNewLadder(t *testing.T) {
+	notify := []config.EscalationAction{{Type: actionNotify, Severity: "critical"}}
+
+	tests := []struct {
+		name       string
+		validator  config.Validator
+		rungs      int
+		shouldFail bool
+	}{
+		{"default", config.Validator{MissedBlocksThreshold: 4, MissedBlockRemoveAfter: 24}, 2, false},
+		{"default without window", config.Validator{MissedBlocksThreshold: 4}, 2, false},
+		{"window defaults to remove after", config.Validator{MissedBlockRemoveAfter: 24, Escalation: []config.Escalation{{Missed: 2, Actions: notify}}}, 1, false},
+		{"policy without window", config.Validator{Escalation: []config.Escalation{{Policy: true, Actions: notify}}}, 1, false},
+		{"no window", config.Validator{Escalation: []config.Escalation{{Rate: 50, Actions: notify}}}, 0, true},
+		{"window larger than remove after", config.Validator{MissedBlockRemoveAfter: 24, Escalation: []config.Escalation{{Missed: 2, Window: 48, Actions: notify}}}, 0, true},
+		{"no condition", config.Validator{MissedBlockRemoveAfter: 24, Escalation: []config.Escalation{{Actions: notify}}}, 0, true},
+		{"no actions", config.Validator{MissedBlockRemoveAfter: 24, Escalation: []config.Escalation{{Missed: 2}}}, 0, true},
+		{"unknown action", config.Validator{MissedBlockRemoveAfter: 24, Escalation: []config.Escalation{{Missed: 2, Actions: []config.EscalationAction{{Type: "sms"}}}}}, 0, true},
+		{"unknown severity", config.Validator{MissedBlockRemoveAfter: 24, Escalation: []config.Escalation{{Missed: 2, Actions: []config.EscalationAction{{Type: actionNotify, Severity: "urgent"}}}}}, 0, true},
+		{"hook without target", config.Validator{MissedBlockRemoveAfter: 24, Escalation: []config.Escalation{{Missed: 2, Actions: []config.EscalationAction{{Type: actionHook}}}}}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ladder, err := newLadder(tt.validator)
+
+			if tt.shouldFail {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if len(ladder) != tt.rungs {
+				t.Fatalf("wrong number of rungs: expected %d, got %d", tt.rungs, len(ladder))
+			}
+		})
+	}
+}
+
+func TestWatcher_UpdateEscalation(t *testing.T) {
+	w := &watcher{
+		ladder:     []rung{{missed: 1, window: 5}, {missed: 2, window: 5}},
+		escalation: -1,
+	}
+
+	steps := []struct {
+		missed    []int
+		height    int
+		reached   int
+		escalated bool
+	}{
+		{[]int{100}, 100, 0, true},
+		{[]int{100, 101}, 101, 1, true},
+		{[]int{100, 101, 102}, 102, 1, false},
+		{[]int{100, 101, 102}, 106, 0, false},
+		{[]int{102, 106, 107}, 107, 1, true},
+	}
+
+	for i, step := range steps {
+		w.missedBlocks = step.missed
+
+		reached, escalated := w.updateEscalation(step.height)
+
+		if reached != step.reached || escalated != step.escalated {
+			t.Fatalf("step %d: expected rung %d (escalated %v), got %d (escalated %v)", i, step.reached, step.escalated, reached, escalated)
+		}
+	}
+}
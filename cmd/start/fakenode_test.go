@@ -0,0 +1,253 @@
+package start
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"minter-sentinel/config"
+	"minter-sentinel/services/minter/node"
+	"minter-sentinel/services/notifier"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+const testPublicKey = "Mp61022c1428f17e02e5b3b130564ab3d37d41ad32ba361b5704642f079888c821"
+
+// fakeNode is a Node API serving a single candidate. Blocks are signed by the candidate unless listed in missed,
+// accepted transactions are included in the next block.
+type fakeNode struct {
+	*httptest.Server
+
+	mu              sync.Mutex
+	height          int
+	candidate       node.CandidateResponse
+	candidateErrors int
+	missed          map[int]bool
+	addresses       map[string]node.GetAddressResponse
+	rejectSends     int
+	rejectCode      int
+	offOnInclude    bool
+	sent            []string
+	included        map[string]int
+}
+
+func newFakeNode(t *testing.T) *fakeNode {
+	n := &fakeNode{
+		height: 100,
+		candidate: node.CandidateResponse{
+			ControlAddress: "Mx0000000000000000000000000000000000000001",
+			Status:         node.CandidateStatusOnline,
+			Validator:      true,
+		},
+		missed:     map[int]bool{},
+		addresses:  map[string]node.GetAddressResponse{},
+		rejectCode: 400,
+		included:   map[string]int{},
+	}
+
+	n.Server = httptest.NewServer(http.HandlerFunc(n.serve))
+
+	t.Cleanup(n.Close)
+
+	return n
+}
+
+func (n *fakeNode) serve(w http.ResponseWriter, r *http.Request) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch path[0] {
+	case "status":
+		n.write(w, http.StatusOK, fmt.Sprintf(`{"latest_block_height":"%d","catching_up":false}`, n.height))
+	case "candidate":
+		if n.candidateErrors > 0 {
+			n.candidateErrors--
+			n.write(w, http.StatusBadGateway, `{}`)
+			return
+		}
+
+		n.writeJSON(w, n.candidate)
+	case "block":
+		height, _ := strconv.Atoi(path[1])
+
+		if height > n.height {
+			n.write(w, http.StatusNotFound, `{"error":{"code":"404","message":"Block not found"}}`)
+			return
+		}
+
+		n.write(w, http.StatusOK, fmt.Sprintf(`{"height":"%d","validators":[{"public_key":"%s","signed":%v}]}`, height, testPublicKey, !n.missed[height]))
+	case "address":
+		address, ok := n.addresses[path[1]]
+
+		if !ok {
+			address = node.GetAddressResponse{Balance: []node.Balance{{Value: "1000000000000000000"}}}
+		}
+
+		n.writeJSON(w, address)
+	case "min_gas_price":
+		n.write(w, http.StatusOK, `{"min_gas_price":"1"}`)
+	case "estimate_tx_commission":
+		n.write(w, http.StatusOK, `{"commission":"1000"}`)
+	case "send_transaction":
+		var req node.SendTransactionRequest
+
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		n.sent = append(n.sent, req.Tx)
+
+		if n.rejectSends > 0 {
+			n.rejectSends--
+			n.write(w, http.StatusBadRequest, fmt.Sprintf(`{"error":{"code":"%d","message":"rejected"}}`, n.rejectCode))
+			return
+		}
+
+		hash := fmt.Sprintf("Mt%064x", len(n.sent))
+
+		n.height++
+		n.included[hash] = n.height
+
+		if n.offOnInclude {
+			n.candidate.Status = node.CandidateStatusOffline
+		}
+
+		n.write(w, http.StatusOK, fmt.Sprintf(`{"code":"0","hash":"%s"}`, hash))
+	case "transaction":
+		height, ok := n.included[path[1]]
+
+		if !ok {
+			n.write(w, http.StatusNotFound, `{"error":{"code":"404","message":"Transaction not found"}}`)
+			return
+		}
+
+		n.write(w, http.StatusOK, fmt.Sprintf(`{"hash":"%s","height":"%d","code":"0"}`, path[1], height))
+	default:
+		n.write(w, http.StatusNotFound, `{}`)
+	}
+}
+
+func (n *fakeNode) write(w http.ResponseWriter, code int, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	_, _ = w.Write([]byte(body))
+}
+
+func (n *fakeNode) writeJSON(w http.ResponseWriter, v interface{}) {
+	body, _ := json.Marshal(v)
+
+	n.write(w, http.StatusOK, string(body))
+}
+
+func (n *fakeNode) update(f func(n *fakeNode)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f(n)
+}
+
+func (n *fakeNode) sentTransactions() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]string(nil), n.sent...)
+}
+
+type recorder struct {
+	mu       sync.Mutex
+	messages []notifier.Message
+}
+
+func (r *recorder) Name() string {
+	return "recorder"
+}
+
+func (r *recorder) Notify(msg notifier.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.messages = append(r.messages, msg)
+
+	return nil
+}
+
+func (r *recorder) texts() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var texts []string
+
+	for _, msg := range r.messages {
+		texts = append(texts, msg.Text)
+	}
+
+	return texts
+}
+
+// contains reports whether any notification contains text.
+func (r *recorder) contains(text string) bool {
+	for _, t := range r.texts() {
+		if strings.Contains(t, text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newTestWatcher creates watcher of testPublicKey against the fake node, with notifications sent to recorder.
+func newTestWatcher(t *testing.T, n *fakeNode, cfg config.Minter) (*watcher, *recorder) {
+	log := logrus.New()
+	log.SetOutput(ioutil.Discard)
+
+	minter, err := node.New([]string{n.URL}, true, log)
+
+	if err != nil {
+		t.Fatalf("failed to create node service: %s", err)
+	}
+
+	if cfg.Sleep == 0 {
+		cfg.Sleep = 1
+	}
+
+	cfg.Testnet = true
+
+	cmd := &Command{
+		log:    log,
+		config: &config.Config{Minter: cfg},
+		minter: minter,
+	}
+
+	r := &recorder{}
+
+	n.mu.Lock()
+	controlAddress := n.candidate.ControlAddress
+	n.mu.Unlock()
+
+	notifications := notifier.New(log)
+	notifications.Add(r)
+
+	v := config.Validator{PublicKey: testPublicKey, MissedBlocksThreshold: 3, MissedBlockRemoveAfter: 24}
+
+	w := newWatcher(cmd, v, notifications)
+	w.controlAddress = controlAddress
+
+	if w.ladder, err = newLadder(v); err != nil {
+		t.Fatalf("failed to create ladder: %s", err)
+	}
+
+	if w.policy, err = newPolicy(v); err != nil {
+		t.Fatalf("failed to create policy: %s", err)
+	}
+
+	cmd.watchers = []*watcher{w}
+
+	return w, r
+}
@@ -0,0 +1,58 @@
+package start
+
+import (
+	"fmt"
+	"minter-sentinel/config"
+	"minter-sentinel/services/keystore"
+)
+
+// unlockKeystores decrypts keystores of all validators with a single passphrase. Keys are held in memory only.
+func (cmd *Command) unlockKeystores(validators []config.Validator) (map[string][]keystore.Key, error) {
+	keys := map[string][]keystore.Key{}
+
+	var passphrase []byte
+
+	for _, v := range validators {
+		if len(v.Keystore) == 0 {
+			continue
+		}
+
+		if _, ok := keys[v.Keystore]; ok {
+			continue
+		}
+
+		f, err := keystore.Load(v.Keystore)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if passphrase == nil {
+			p, err := keystore.Passphrase(cmd.config.Keystore.PassphraseFile, cmd.config.Keystore.PassphraseEnv)
+
+			if err != nil {
+				return nil, err
+			}
+
+			passphrase = p
+		}
+
+		k, err := f.Decrypt(passphrase)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to unlock keystore %s: %w", v.Keystore, err)
+		}
+
+		cmd.log.WithField("path", v.Keystore).
+			WithField("addresses", f.Addresses).
+			Println("Keystore unlocked")
+
+		keys[v.Keystore] = k
+	}
+
+	for i := range passphrase {
+		passphrase[i] = 0
+	}
+
+	return keys, nil
+}
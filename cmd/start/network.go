@@ -0,0 +1,44 @@
+package start
+
+import (
+	"fmt"
+	"minter-sentinel/services/notifier"
+)
+
+// isNetworkMissed reports whether the block is missed by so many validators that it's a network problem rather than ours.
+func (w *watcher) isNetworkMissed(fraction float64) bool {
+	excludeAbove := w.cmd.config.Minter.NetworkMissed.ExcludeAbove
+
+	return excludeAbove > 0 && fraction*100 > excludeAbove
+}
+
+// excludeBlock counts block missed network-wide. Notification is sent once per episode, not for every excluded block.
+func (w *watcher) excludeBlock(height int) {
+	if w.cmd.prometheus != nil {
+		w.cmd.prometheus.BlocksExcludedIncrement(w.validator.PublicKey)
+	}
+
+	w.newLogEntry(height).
+		WithField("network_missed", w.networkMissed).
+		WithField("exclude_above", w.cmd.config.Minter.NetworkMissed.ExcludeAbove).
+		Warnln("Block missed network-wide. Not counted as missed")
+
+	w.excludedBlocks++
+
+	if w.excludedBlocks == 1 {
+		w.notify(notifier.Info, fmt.Sprintf("ℹ️ Block %d missed by %.0f%% of validators. Blocks missed network-wide are not counted as missed", height, w.networkMissed*100))
+	}
+}
+
+// endNetworkMissed reports the end of network-wide misses episode.
+func (w *watcher) endNetworkMissed(height int) {
+	if w.excludedBlocks == 0 {
+		return
+	}
+
+	w.newLogEntry(height).WithField("excluded", w.excludedBlocks).Println("Network recovered")
+
+	w.notify(notifier.Info, fmt.Sprintf("✅ Network recovered at block %d, %d blocks were not counted as missed", height, w.excludedBlocks))
+
+	w.excludedBlocks = 0
+}
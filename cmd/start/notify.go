@@ -0,0 +1,137 @@
+package start
+
+import (
+	"fmt"
+	"minter-sentinel/config"
+	"minter-sentinel/services/discord"
+	"minter-sentinel/services/notifier"
+	"minter-sentinel/services/slack"
+	"minter-sentinel/services/telegram"
+	"minter-sentinel/services/webhook"
+)
+
+type backend struct {
+	name       string
+	notifier   notifier.Notifier
+	severities []notifier.Severity
+}
+
+func (cmd *Command) setupNotifiers() error {
+	add := func(n notifier.Notifier, severities []string) error {
+		s, err := notifier.ParseSeverities(severities)
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", n.Name(), err)
+		}
+
+		cmd.backends = append(cmd.backends, backend{name: n.Name(), notifier: n, severities: s})
+
+		return nil
+	}
+
+	if len(cmd.config.Telegram.Token) > 0 {
+		t, err := telegram.New(cmd.config.Telegram.Token, cmd.config.Telegram.Admins)
+
+		if err != nil {
+			return err
+		}
+
+		cmd.telegram = t
+
+		if err := add(t, cmd.config.Telegram.Severities); err != nil {
+			return err
+		}
+	} else {
+		cmd.log.Warn("Telegram token not set. Telegram notifications will not be sent")
+	}
+
+	if len(cmd.config.Slack.WebhookURL) > 0 {
+		s, err := slack.New(cmd.config.Slack.WebhookURL)
+
+		if err != nil {
+			return err
+		}
+
+		if err := add(s, cmd.config.Slack.Severities); err != nil {
+			return err
+		}
+	}
+
+	if len(cmd.config.Discord.WebhookURL) > 0 {
+		d, err := discord.New(cmd.config.Discord.WebhookURL)
+
+		if err != nil {
+			return err
+		}
+
+		if err := add(d, cmd.config.Discord.Severities); err != nil {
+			return err
+		}
+	}
+
+	if len(cmd.config.Webhook.URL) > 0 {
+		h, err := webhook.New(cmd.config.Webhook.URL, cmd.config.Webhook.Secret)
+
+		if err != nil {
+			return err
+		}
+
+		if err := add(h, cmd.config.Webhook.Severities); err != nil {
+			return err
+		}
+	}
+
+	if len(cmd.backends) == 0 {
+		cmd.log.Warn("No notifiers configured. Notifications will not be sent")
+	}
+
+	cmd.notifier = notifier.New(cmd.log)
+
+	for _, b := range cmd.backends {
+		cmd.notifier.Add(b.notifier, b.severities...)
+	}
+
+	return nil
+}
+
+// newValidatorNotifier routes validator's messages to the notifiers listed for it,
+// using validator's own Telegram admins if they are set.
+func (cmd *Command) newValidatorNotifier(v config.Validator) (*notifier.Service, error) {
+	enabled := map[string]bool{}
+
+	for _, name := range v.Notifiers {
+		switch name {
+		case "telegram", "slack", "discord", "webhook":
+			enabled[name] = true
+		default:
+			return nil, fmt.Errorf("unknown notifier: %s", name)
+		}
+	}
+
+	n := notifier.New(cmd.log)
+
+	for _, b := range cmd.backends {
+		if len(enabled) > 0 && !enabled[b.name] {
+			continue
+		}
+
+		if b.name == "telegram" && len(v.TelegramAdmins) > 0 {
+			n.Add(cmd.telegram.WithAdmins(v.TelegramAdmins), b.severities...)
+			continue
+		}
+
+		n.Add(b.notifier, b.severities...)
+	}
+
+	return n, nil
+}
+
+func (cmd *Command) notify(n *notifier.Service, msg notifier.Message) {
+	cmd.wg.Add(1)
+
+	go func() {
+		defer cmd.wg.Done()
+
+		n.Notify(msg)
+	}()
+}
@@ -0,0 +1,64 @@
+package start
+
+import (
+	"fmt"
+	"minter-sentinel/config"
+	"minter-sentinel/services/policy"
+)
+
+// newPolicy builds missed blocks policy of validator. Sliding window of missed_block_remove_after blocks is used by default.
+func newPolicy(v config.Validator) (policy.Policy, error) {
+	c := v.Policy
+
+	if c.Window == 0 {
+		c.Window = v.MissedBlockRemoveAfter
+	}
+
+	if c.Threshold == 0 {
+		c.Threshold = v.MissedBlocksThreshold
+	}
+
+	if c.Threshold <= 0 && (c.Type == "" || c.Type == policy.TypeSliding || c.Type == policy.TypeConsecutive) {
+		return nil, fmt.Errorf("%s policy: threshold must be positive, set threshold or missed_blocks_threshold", policyType(c))
+	}
+
+	window := c.Window
+
+	switch c.Type {
+	case policy.TypeConsecutive:
+		window = c.Threshold
+	case policy.TypeMinterJail:
+		window = policy.MinterMaxAbsentWindow
+	}
+
+	if window <= 0 {
+		return nil, fmt.Errorf("%s policy: window must be positive, set window or missed_block_remove_after", policyType(c))
+	}
+
+	// missed blocks are kept (and saved between restarts) for missed_block_remove_after blocks only,
+	// so policy would lose its history otherwise
+	if window > v.MissedBlockRemoveAfter {
+		return nil, fmt.Errorf("%s policy: window of %d blocks can't be larger than missed_block_remove_after", policyType(c), window)
+	}
+
+	switch c.Type {
+	case "", policy.TypeSliding:
+		return policy.NewSliding(c.Window, c.Threshold), nil
+	case policy.TypeConsecutive:
+		return policy.NewConsecutive(c.Threshold), nil
+	case policy.TypePercentage:
+		return policy.NewPercentage(c.Window, c.Percent)
+	case policy.TypeMinterJail:
+		return policy.NewMinterJail(c.Margin)
+	default:
+		return nil, fmt.Errorf("unknown policy: %s", c.Type)
+	}
+}
+
+func policyType(c config.Policy) string {
+	if c.Type == "" {
+		return policy.TypeSliding
+	}
+
+	return c.Type
+}
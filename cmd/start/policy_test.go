@@ -0,0 +1,41 @@
+package start
+
+import (
+	"minter-sentinel/config"
+	"testing"
+)
+
+func TestNewPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		validator  config.Validator
+		shouldFail bool
+	}{
+		{"default", config.Validator{MissedBlocksThreshold: 4, MissedBlockRemoveAfter: 24}, false},
+		{"default without threshold", config.Validator{MissedBlockRemoveAfter: 24}, true},
+		{"default without window", config.Validator{MissedBlocksThreshold: 4}, true},
+		{"sliding window larger than remove after", config.Validator{MissedBlockRemoveAfter: 24, Policy: config.Policy{Type: "sliding", Window: 48, Threshold: 4}}, true},
+		{"consecutive", config.Validator{MissedBlockRemoveAfter: 24, Policy: config.Policy{Type: "consecutive", Threshold: 3}}, false},
+		{"consecutive without threshold", config.Validator{MissedBlockRemoveAfter: 24, Policy: config.Policy{Type: "consecutive"}}, true},
+		{"consecutive longer than remove after", config.Validator{MissedBlockRemoveAfter: 24, Policy: config.Policy{Type: "consecutive", Threshold: 30}}, true},
+		{"percentage", config.Validator{MissedBlockRemoveAfter: 100, Policy: config.Policy{Type: "percentage", Window: 100, Percent: 10}}, false},
+		{"percentage window larger than remove after", config.Validator{MissedBlockRemoveAfter: 24, Policy: config.Policy{Type: "percentage", Window: 100, Percent: 10}}, true},
+		{"minter jail", config.Validator{MissedBlockRemoveAfter: 24, Policy: config.Policy{Type: "minter_jail", Margin: 2}}, false},
+		{"minter jail with short remove after", config.Validator{MissedBlockRemoveAfter: 12, Policy: config.Policy{Type: "minter_jail"}}, true},
+		{"unknown", config.Validator{MissedBlocksThreshold: 4, MissedBlockRemoveAfter: 24, Policy: config.Policy{Type: "random"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newPolicy(tt.validator)
+
+			if tt.shouldFail && err == nil {
+				t.Fatalf("expected error")
+			}
+
+			if !tt.shouldFail && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
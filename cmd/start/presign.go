@@ -0,0 +1,121 @@
+package start
+
+import (
+	"context"
+	"time"
+)
+
+const defaultPresignInterval = 10
+
+type presigned struct {
+	tx       string
+	nonce    uint64
+	gasPrice uint8
+	signedAt time.Time
+}
+
+// presign keeps a ready-signed turn off transaction, so turning off masternode takes a single broadcast.
+// Transaction is signed again when nonce of control address or min gas price changes.
+func (w *watcher) presign(ctx context.Context) {
+	if !w.canSign() {
+		return
+	}
+
+	interval := w.cmd.config.Minter.Presign.Interval
+
+	if interval <= 0 {
+		interval = defaultPresignInterval
+	}
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		w.refreshPresigned()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *watcher) refreshPresigned() {
+	defer w.observePresigned()
+
+	c, err := w.cmd.minter.NewCandidateOff(w.validator.PublicKey, w.controlAddress)
+
+	w.checkGasPrice(err)
+
+	if err != nil {
+		w.backgroundLogEntry().WithError(err).Warnln("Failed to refresh pre-signed transaction")
+		return
+	}
+
+	// transaction that can't pay commission would be rejected, so it's not kept
+	if err := w.checkCommission(c); err != nil {
+		w.mu.Lock()
+		w.presigned = nil
+		w.mu.Unlock()
+
+		return
+	}
+
+	w.mu.RLock()
+	cached := w.presigned
+	w.mu.RUnlock()
+
+	if cached != nil && cached.nonce == c.Nonce && cached.gasPrice == c.GasPrice {
+		return
+	}
+
+	tx, err := w.signCandidateOff(c)
+
+	if err != nil {
+		w.backgroundLogEntry().WithError(err).Warnln("Failed to pre-sign transaction")
+		return
+	}
+
+	w.mu.Lock()
+	w.presigned = &presigned{
+		tx:       tx,
+		nonce:    c.Nonce,
+		gasPrice: c.GasPrice,
+		signedAt: time.Now(),
+	}
+	w.mu.Unlock()
+
+	w.backgroundLogEntry().
+		WithField("nonce", c.Nonce).
+		WithField("gas_price", c.GasPrice).
+		Println("Turn off transaction pre-signed")
+}
+
+// takePresigned returns cached transaction and removes it, so it's not sent twice with the same nonce.
+func (w *watcher) takePresigned() *presigned {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	p := w.presigned
+	w.presigned = nil
+
+	return p
+}
+
+func (w *watcher) observePresigned() {
+	if w.cmd.prometheus == nil {
+		return
+	}
+
+	w.mu.RLock()
+	p := w.presigned
+	w.mu.RUnlock()
+
+	if p == nil {
+		w.cmd.prometheus.SetPresignedTransactionAge(w.validator.PublicKey, -1)
+		return
+	}
+
+	w.cmd.prometheus.SetPresignedTransactionAge(w.validator.PublicKey, time.Since(p.signedAt).Seconds())
+}
@@ -0,0 +1,94 @@
+package start
+
+import (
+	"context"
+	"minter-sentinel/services/minter/node"
+	"minter-sentinel/services/notifier"
+	"time"
+)
+
+const defaultRearmInterval = 10
+
+// waitUntilOnline keeps watcher armed after turn off: it polls the candidate until it's online and signing again,
+// then resets missed blocks window to resume watching. Returns false if watcher is stopped.
+func (w *watcher) waitUntilOnline(ctx context.Context) bool {
+	interval := w.cmd.config.Minter.Rearm.Interval
+
+	if interval <= 0 {
+		interval = defaultRearmInterval
+	}
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	w.newLogEntry(w.lastBlock).Println("Waiting for candidate to be online again")
+	w.notify(notifier.Info, "⏸ Waiting for masternode to be set online again")
+
+	online := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-w.resetRequests:
+		case <-w.turnOffRequests:
+		case <-ticker.C:
+		}
+
+		candidate, err := w.cmd.minter.GetCandidate(w.validator.PublicKey)
+
+		if err != nil {
+			w.newLogEntry(w.lastBlock).WithError(err).Warnln("Failed to get candidate")
+			continue
+		}
+
+		if candidate.Status != node.CandidateStatusOnline {
+			online = false
+			continue
+		}
+
+		if !online {
+			online = true
+
+			w.newLogEntry(w.lastBlock).Println("Candidate is online. Waiting for signed blocks")
+			w.notify(notifier.Info, "🔄 Masternode is online. Waiting for signed blocks")
+		}
+
+		height, err := w.cmd.lastBlockHeight()
+
+		if err != nil {
+			continue
+		}
+
+		signed, err := w.isSigned(height)
+
+		if err != nil || !signed {
+			continue
+		}
+
+		w.rearm(height)
+
+		return true
+	}
+}
+
+func (w *watcher) rearm(height int) {
+	w.mu.Lock()
+	w.lastBlock = height
+	w.missedBlocks = nil
+	w.outOfSet = false
+	w.errors = checkErrors{}
+	w.mu.Unlock()
+
+	w.policy.Reset()
+	w.escalation = -1
+
+	if w.cmd.prometheus != nil {
+		w.cmd.prometheus.SetBlocksMissedCurrent(w.validator.PublicKey, 0)
+	}
+
+	w.saveState()
+
+	w.newLogEntry(height).Println("Candidate is signing blocks again. Watching resumed")
+	w.notify(notifier.Info, "✅ Masternode is signing blocks again. Watching resumed")
+}
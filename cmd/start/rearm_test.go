@@ -0,0 +1,122 @@
+package start
+
+import (
+	"context"
+	"minter-sentinel/config"
+	"minter-sentinel/services/minter/node"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitFor polls condition until it's true or fails the test after a few seconds.
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if condition() {
+			return
+		}
+	}
+
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func countMessages(r *recorder, text string) int {
+	count := 0
+
+	for _, t := range r.texts() {
+		if strings.Contains(t, text) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestWatcher_WaitUntilOnline(t *testing.T) {
+	n := newFakeNode(t)
+	n.candidate.Status = node.CandidateStatusOffline
+
+	w, r := newTestWatcher(t, n, config.Minter{Rearm: config.Rearm{Interval: 1}})
+	w.missedBlocks = []int{95, 96, 97}
+	w.escalation = 1
+
+	done := make(chan bool, 1)
+
+	go func() {
+		done <- w.waitUntilOnline(context.Background())
+	}()
+
+	waitFor(t, "waiting notification", func() bool { return r.contains("Waiting for masternode to be set online again") })
+
+	// online, but the last block is not signed yet
+	n.update(func(n *fakeNode) {
+		n.candidate.Status = node.CandidateStatusOnline
+		n.missed[n.height] = true
+	})
+
+	waitFor(t, "online notification", func() bool { return r.contains("Masternode is online. Waiting for signed blocks") })
+
+	time.Sleep(1500 * time.Millisecond)
+
+	if count := countMessages(r, "Masternode is online"); count != 1 {
+		t.Fatalf("online should be notified once, got %d: %v", count, r.texts())
+	}
+
+	// offline again and back online is notified again
+	n.update(func(n *fakeNode) {
+		n.candidate.Status = node.CandidateStatusOffline
+	})
+
+	time.Sleep(1500 * time.Millisecond)
+
+	n.update(func(n *fakeNode) {
+		n.candidate.Status = node.CandidateStatusOnline
+	})
+
+	waitFor(t, "second online notification", func() bool { return countMessages(r, "Masternode is online") == 2 })
+
+	select {
+	case <-done:
+		t.Fatalf("watcher should not be rearmed before validator signs blocks")
+	default:
+	}
+
+	n.update(func(n *fakeNode) {
+		n.height++
+	})
+
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Fatalf("watcher should be rearmed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for rearm")
+	}
+
+	w.cmd.wg.Wait()
+
+	if !r.contains("Masternode is signing blocks again. Watching resumed") {
+		t.Fatalf("expected resume notification, got %v", r.texts())
+	}
+
+	if w.lastBlock != 101 || len(w.missedBlocks) != 0 || w.escalation != -1 {
+		t.Fatalf("watcher should be rearmed at block 101 with empty window, got block %d, missed %v, escalation %d", w.lastBlock, w.missedBlocks, w.escalation)
+	}
+}
+
+func TestWatcher_WaitUntilOnline_Stop(t *testing.T) {
+	n := newFakeNode(t)
+	n.candidate.Status = node.CandidateStatusOffline
+
+	w, _ := newTestWatcher(t, n, config.Minter{Rearm: config.Rearm{Interval: 1}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if w.waitUntilOnline(ctx) {
+		t.Fatalf("stopped watcher should not be rearmed")
+	}
+}
@@ -1,36 +1,59 @@
 package start
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"minter-sentinel/config"
+	"minter-sentinel/services/api"
+	"minter-sentinel/services/keystore"
 	"minter-sentinel/services/minter/node"
+	"minter-sentinel/services/notifier"
 	"minter-sentinel/services/prometheus"
+	"minter-sentinel/services/signer"
+	"minter-sentinel/services/state"
 	"minter-sentinel/services/telegram"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 )
 
-var NoValidatorsSignedYet = errors.New("no validators signed")
+var (
+	NoValidatorsSignedYet = errors.New("no validators signed")
+	QuorumNotReached      = errors.New("quorum not reached")
+	InsufficientBalance   = errors.New("balance of control address is not enough to pay commission of turn off transaction")
+)
+
+const (
+	resubscribeInterval        = 10 * time.Second
+	defaultHealthCheckInterval = 10
+)
 
 type Command struct {
+	// lastEvent is unix time in nanoseconds of the last new block event, first field to keep it aligned for atomic access.
+	lastEvent int64
+
 	log    *logrus.Logger
 	config *config.Config
 
 	wg sync.WaitGroup
 
-	dryRun         bool
-	missedBlocks   []int
-	lastBlock      int
-	controlAddress string
+	dryRun     bool
+	wait       bool
+	subscribed int32
+	watchers   []*watcher
 
 	minter     *node.Service
-	telegram   *tgbotapi.BotAPI
+	telegram   *telegram.Service
+	notifier   *notifier.Service
+	backends   []backend
 	prometheus *prometheus.Service
+	state      *state.Service
+	signer     *signer.Client
 }
 
 func New(log *logrus.Logger, config *config.Config) *Command {
@@ -48,6 +71,12 @@ func (cmd *Command) Command() *cli.Command {
 			Value:    false,
 			Usage:    "Don't send transaction to set masternode off",
 		},
+		&cli.BoolFlag{
+			Name:     "wait",
+			Required: false,
+			Value:    false,
+			Usage:    "Wait until candidate is online and signing blocks instead of refusing to start",
+		},
 	}
 
 	return &cli.Command{
@@ -56,18 +85,46 @@ func (cmd *Command) Command() *cli.Command {
 		Flags: flags,
 		Action: func(ctx *cli.Context) error {
 			cmd.dryRun = ctx.Bool("dry-run")
+			cmd.wait = ctx.Bool("wait")
+
+			validators := cmd.config.Minter.ValidatorList()
 
-			if !cmd.dryRun && len(cmd.config.Minter.TransactionOff) == 0 && len(cmd.config.Minter.Seeds) == 0 {
-				return errors.New("`transaction_off` or `seeds` are not set in configuration file")
+			if err := cmd.setupRemoteSigner(); err != nil {
+				return err
+			}
+
+			if err := cmd.validateValidators(validators); err != nil {
+				return err
+			}
+
+			keys, err := cmd.unlockKeystores(validators)
+
+			if err != nil {
+				return err
 			}
 
 			if len(cmd.config.Minter.NodeApi) == 0 {
 				return errors.New("define at least one node_api in configuration file")
 			}
 
+			if cmd.config.Minter.Quorum > len(cmd.config.Minter.NodeApi) {
+				return errors.New("`quorum` can not be greater than number of node_api in configuration file")
+			}
+
 			if n, err := node.New(cmd.config.Minter.NodeApi, cmd.config.Minter.Testnet, cmd.log); err != nil {
 				return err
 			} else {
+				n.SetCircuitBreaker(
+					cmd.config.Minter.Failover.FailureThreshold,
+					time.Duration(cmd.config.Minter.Failover.Cooldown)*time.Second,
+				)
+				n.SetTimeout(time.Duration(cmd.config.Minter.Failover.Timeout) * time.Second)
+				n.SetGas(
+					cmd.config.Minter.Gas.Coin,
+					cmd.config.Minter.Gas.PriceMultiplier,
+					cmd.config.Minter.Gas.MaxPrice,
+				)
+
 				if err := n.Ping(); err != nil {
 					return err
 				}
@@ -80,33 +137,37 @@ func (cmd *Command) Command() *cli.Command {
 					return err
 				} else {
 					cmd.prometheus = p
-					cmd.prometheus.SetMissedBlocksThreshold(cmd.config.Minter.MissedBlocksThreshold)
 					cmd.prometheus.SetSleep(cmd.config.Minter.Sleep)
+					cmd.minter.SetObserver(cmd.prometheus.ObserveNodeRequest)
+
+					for _, v := range validators {
+						cmd.prometheus.SetMissedBlocksThreshold(v.PublicKey, v.MissedBlocksThreshold)
+					}
 
 					go func() {
 						if err := cmd.prometheus.Start(); err != nil {
-							cmd.newLogEntry(cmd.lastBlock).Fatalln(err)
+							cmd.log.Fatalln(err)
 						}
 					}()
 				}
 			}
 
-			if len(cmd.config.Telegram.Token) > 0 {
-				b, err := telegram.New(cmd.config.Telegram.Token)
+			if err := cmd.setupNotifiers(); err != nil {
+				return err
+			}
+
+			if len(cmd.config.State.Path) > 0 {
+				s, err := state.New(cmd.config.State.Path, cmd.config.Minter.Testnet)
 
 				if err != nil {
 					return err
 				}
 
-				cmd.telegram = b
-			} else {
-				cmd.log.Warn("Telegram token not set. Notifications will not be sent")
-			}
-
-			candidate, err := cmd.minter.GetCandidate(cmd.config.Minter.PublicKey)
+				if err := s.Load(); err != nil {
+					return fmt.Errorf("failed to load state from %s: %w", s.Path(), err)
+				}
 
-			if err != nil {
-				return err
+				cmd.state = s
 			}
 
 			lastBlock, err := cmd.lastBlockHeight()
@@ -115,250 +176,305 @@ func (cmd *Command) Command() *cli.Command {
 				return err
 			}
 
-			if candidate.JailedUntil > 0 && candidate.JailedUntil > lastBlock {
-				return errors.New(
-					fmt.Sprintf(
-						"candidate is jailed until block %d, current block: %d, blocks until unjail: %d (approx. %d sec.)",
-						candidate.JailedUntil,
-						lastBlock,
-						candidate.JailedUntil-lastBlock,
-						(candidate.JailedUntil-lastBlock)*5,
-					),
-				)
-			}
+			for _, v := range validators {
+				n, err := cmd.newValidatorNotifier(v)
 
-			if !candidate.Validator {
-				return errors.New("candidate is not a validator yet")
-			}
+				if err != nil {
+					return fmt.Errorf("%s: %w", v.PublicKey, err)
+				}
 
-			if candidate.Status != 2 {
-				return errors.New("candidate is not online")
-			}
+				w := newWatcher(cmd, v, n)
+				w.keys = keys[v.Keystore]
 
-			signed, err := cmd.isSigned(lastBlock)
+				if w.ladder, err = newLadder(v); err != nil {
+					return fmt.Errorf("%s: %w", v.PublicKey, err)
+				}
 
-			if err != nil {
-				return err
-			}
+				if w.policy, err = newPolicy(v); err != nil {
+					return fmt.Errorf("%s: %w", v.PublicKey, err)
+				}
+
+				if len(v.Seeds) > 0 {
+					if w.keys, err = keystore.FromSeeds(v.Seeds); err != nil {
+						return fmt.Errorf("%s: invalid seed: %w", v.PublicKey, err)
+					}
+				}
 
-			if !signed {
-				return errors.New("last block is not signed by the validator, start watcher when validator starts signing blocks")
+				if err := w.prepare(lastBlock); err != nil {
+					return fmt.Errorf("%s: %w", v.PublicKey, err)
+				}
+
+				cmd.watchers = append(cmd.watchers, w)
 			}
 
-			cmd.lastBlock = lastBlock
-			cmd.controlAddress = candidate.ControlAddress
+			if err := cmd.startApi(); err != nil {
+				return err
+			}
 
 			return cmd.run()
 		},
 	}
 }
 
-func (cmd *Command) run() error {
-	cmd.newLogEntry(cmd.lastBlock).
-		WithField("missed_blocks_threshold", cmd.config.Minter.MissedBlocksThreshold).
-		WithField("sleep", cmd.config.Minter.Sleep).
-		WithField("missed_block_remove_after", cmd.config.Minter.MissedBlockRemoveAfter).
-		WithField("control_address", cmd.controlAddress).
-		Println("Watcher started")
+func (cmd *Command) validateValidators(validators []config.Validator) error {
+	switch cmd.config.Minter.TransactionOffValidation {
+	case "", validationWarn, validationStrict:
+	default:
+		return fmt.Errorf("unknown `transaction_off_validation` %s, use warn or strict", cmd.config.Minter.TransactionOffValidation)
+	}
 
-	ticker := time.NewTicker(time.Duration(cmd.config.Minter.Sleep) * time.Second)
+	seen := map[string]bool{}
 
-	turnOff := make(chan bool)
+	for _, v := range validators {
+		if len(v.PublicKey) == 0 {
+			return errors.New("`public_key` is not set in configuration file")
+		}
 
-	go func() {
-		for {
-			select {
-			case <-ticker.C:
-				cmd.cleanupMissedBlocks()
+		if seen[v.PublicKey] {
+			return fmt.Errorf("validator %s is defined more than once in configuration file", v.PublicKey)
+		}
 
-				nextHeight := cmd.lastBlock + 1
+		seen[v.PublicKey] = true
 
-				signed, err := cmd.isSigned(nextHeight)
+		if len(v.Seeds) > 0 && len(v.Keystore) > 0 {
+			return fmt.Errorf("both `seeds` and `keystore` are set in configuration file for validator %s", v.PublicKey)
+		}
 
-				if err != nil {
-					if _, ok := err.(*node.BlockNotFound); ok {
-						cmd.newLogEntry(nextHeight).Debugln("Block not created yet.")
-						continue
-					}
+		if !cmd.dryRun && len(v.TransactionOff) == 0 && len(v.Seeds) == 0 && len(v.Keystore) == 0 && cmd.signer == nil {
+			return fmt.Errorf("`transaction_off`, `seeds`, `keystore` or `remote_signer` are not set in configuration file for validator %s", v.PublicKey)
+		}
+	}
 
-					if errors.Is(err, NoValidatorsSignedYet) {
-						continue
-					}
+	return nil
+}
 
-					go cmd.sendBotMessage(fmt.Sprintf("⚠️ Failed to detect if block is signed: %s", err))
+func (cmd *Command) setupRemoteSigner() error {
+	cfg := cmd.config.Minter.RemoteSigner
 
-					turnOff <- true
+	if len(cfg.URL) == 0 {
+		return nil
+	}
 
-					return
-				}
+	var tlsConfig *tls.Config
 
-				cmd.lastBlock = nextHeight
+	if len(cfg.TLS.Cert) > 0 {
+		c, err := signer.ClientTLSConfig(cfg.TLS.Cert, cfg.TLS.Key, cfg.TLS.CA)
 
-				if signed {
-					go func() {
-						if cmd.prometheus != nil {
-							cmd.prometheus.BlocksSignedIncrement()
-						}
-					}()
+		if err != nil {
+			return err
+		}
 
-					cmd.newLogEntry(nextHeight).Println("Block signed")
+		tlsConfig = c
+	}
 
-					continue
-				}
+	c, err := signer.NewClient(cfg.URL, tlsConfig)
 
-				go func() {
-					if cmd.prometheus != nil {
-						go cmd.prometheus.BlocksMissedIncrement()
-					}
-				}()
+	if err != nil {
+		return err
+	}
+
+	cmd.signer = c
 
-				cmd.missedBlocks = append(cmd.missedBlocks, nextHeight)
+	return nil
+}
 
-				if len(cmd.missedBlocks) > 0 && len(cmd.missedBlocks) < cmd.config.Minter.MissedBlocksThreshold {
-					cmd.newLogEntry(nextHeight).Warnln("Block missed")
+func (cmd *Command) startApi() error {
+	if !cmd.config.Api.Enabled {
+		return nil
+	}
 
-					go cmd.sendBotMessage(fmt.Sprintf("⚠️ Block %d missed [%d/%d]", nextHeight, len(cmd.missedBlocks), cmd.config.Minter.MissedBlocksThreshold))
-				}
+	a, err := api.New(cmd.config.Api.Address, cmd.config.Api.Token, cmd, cmd.log)
 
-				if len(cmd.missedBlocks) >= cmd.config.Minter.MissedBlocksThreshold {
-					cmd.newLogEntry(nextHeight).Errorln("Missed blocks threshold exceeded")
+	if err != nil {
+		return err
+	}
 
-					go cmd.sendBotMessage(fmt.Sprintf("🚨 Block %d missed [%d/%d]", nextHeight, len(cmd.missedBlocks), cmd.config.Minter.MissedBlocksThreshold))
+	if len(cmd.config.Api.Address) == 0 {
+		if cmd.prometheus == nil {
+			return errors.New("`api.address` is not set in configuration file and prometheus is disabled")
+		}
 
-					turnOff <- true
+		a.Register(cmd.prometheus)
 
-					return
-				}
-			case <-turnOff:
-				ticker.Stop()
-				return
-			}
+		return nil
+	}
+
+	go func() {
+		if err := a.Start(); err != nil {
+			cmd.log.Fatalln(err)
 		}
 	}()
 
-	if v := <-turnOff; v {
-		go cmd.sendBotMessage("🚨 Sending transaction to turn off masternode")
+	return nil
+}
 
-		if err := cmd.turnOffMasternode(); err != nil {
-			cmd.newLogEntry(cmd.lastBlock).Errorln("Failed to turn off masternode", err)
+func (cmd *Command) run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-			go cmd.sendBotMessage("🚨 Failed to turn off masternode")
+	if cmd.config.Minter.Subscribe {
+		go cmd.subscribe(ctx)
+	}
 
-			return err
-		}
+	go cmd.checkNodeHealth(ctx)
 
-		go cmd.sendBotMessage("🚨 Masternode is off")
+	if cmd.telegram != nil {
+		go func() {
+			if err := cmd.telegram.Listen(ctx, cmd, cmd.log); err != nil {
+				cmd.log.WithError(err).Errorln("Failed to listen for Telegram commands")
+			}
+		}()
 	}
 
-	cmd.wg.Wait()
+	type watcherError struct {
+		w   *watcher
+		err error
+	}
 
-	return nil
-}
+	errs := make(chan watcherError, len(cmd.watchers))
 
-func (cmd *Command) sendBotMessage(message string) {
-	if cmd.telegram == nil {
-		return
+	for _, w := range cmd.watchers {
+		go func(w *watcher) {
+			errs <- watcherError{w: w, err: w.run(ctx)}
+		}(w)
 	}
 
-	cmd.wg.Add(1)
+	var result error
 
-	for _, id := range cmd.config.Telegram.Admins {
-		msg := tgbotapi.NewMessage(int64(id), message)
+	// The first failed watcher stops the others, so sentinel exits instead of silently watching with a dead watcher.
+	for range cmd.watchers {
+		if e := <-errs; e.err != nil && result == nil {
+			result = e.err
 
-		_, _ = cmd.telegram.Send(msg)
-	}
+			e.w.newLogEntry(e.w.lastBlock).WithError(e.err).Errorln("Watcher failed. Stopping sentinel")
+			e.w.notify(notifier.Critical, fmt.Sprintf("🚨 Watcher failed: %s. Stopping sentinel", e.err))
 
-	cmd.wg.Done()
-}
-
-func (cmd *Command) turnOffMasternode() error {
-	if cmd.dryRun {
-		cmd.newLogEntry(cmd.lastBlock).Warn("⚠️ Dry run. Masternode is still on!")
-		return nil
+			cancel()
+		}
 	}
 
-	go cmd.sendBotMessage("🚨 Setting masternode off...")
+	cmd.wg.Wait()
 
-	tx := cmd.config.Minter.TransactionOff
+	return result
+}
 
-	if len(cmd.config.Minter.Seeds) > 0 {
-		t, err := cmd.minter.GenerateCandidateOffTransaction(
-			cmd.config.Minter.PublicKey,
-			cmd.controlAddress,
-			cmd.config.Minter.Seeds...,
-		)
+func (cmd *Command) subscribe(ctx context.Context) {
+	for {
+		sub, err := cmd.minter.SubscribeNewBlocks(ctx)
 
 		if err != nil {
-			cmd.log.Errorf("failed to generate transaction: %s", err)
+			cmd.log.WithError(err).Warnf("Failed to subscribe to new blocks. Polling every %d sec.", cmd.config.Minter.Sleep)
 		} else {
-			tx = t
-		}
-	}
+			atomic.StoreInt64(&cmd.lastEvent, time.Now().UnixNano())
+			atomic.StoreInt32(&cmd.subscribed, 1)
 
-	resp, err := cmd.minter.SendTransaction(tx)
+			cmd.log.WithField("url", sub.URL()).Println("Subscribed to new blocks")
 
-	if err != nil {
-		return err
-	}
+			cmd.readEvents(sub)
 
-	if resp.Error != nil {
-		cmd.newLogEntry(cmd.lastBlock).Errorln(resp.Error)
-		return errors.New(fmt.Sprintf("[%d] %s", resp.Error.Code, resp.Error.Message))
-	}
+			atomic.StoreInt32(&cmd.subscribed, 0)
 
-	return nil
-}
+			if ctx.Err() != nil {
+				return
+			}
 
-func (cmd *Command) lastBlockHeight() (int, error) {
-	status, err := cmd.minter.Status()
+			cmd.log.WithError(sub.Err()).Warnf("Subscription to new blocks dropped. Polling every %d sec.", cmd.config.Minter.Sleep)
+		}
 
-	if err != nil {
-		return 0, err
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resubscribeInterval):
+		}
 	}
-
-	return status.LatestBlockHeight, nil
 }
 
-func (cmd *Command) isSigned(height int) (bool, error) {
-	block, err := cmd.minter.GetBlock(height)
+// readEvents passes new block events to watchers until subscription is closed. Subscription is closed
+// when no event arrives within the stall timeout, so watchers poll until it's resubscribed.
+func (cmd *Command) readEvents(sub *node.Subscription) {
+	timer := time.NewTimer(cmd.stallTimeout())
+	defer timer.Stop()
 
-	if err != nil {
-		return false, err
-	}
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
 
-	if len(block.Validators) == 0 {
-		return false, NoValidatorsSignedYet
-	}
+			atomic.StoreInt64(&cmd.lastEvent, time.Now().UnixNano())
+
+			for _, w := range cmd.watchers {
+				w.notifyNewBlock(event)
+			}
+
+			if !timer.Stop() {
+				<-timer.C
+			}
 
-	for _, validator := range block.Validators {
-		if validator.PublicKey == cmd.config.Minter.PublicKey && validator.Signed {
-			return true, nil
+			timer.Reset(cmd.stallTimeout())
+		case <-timer.C:
+			cmd.log.WithField("url", sub.URL()).Warnf("No new blocks received in %s. Closing subscription", cmd.stallTimeout())
+
+			_ = sub.Close()
 		}
 	}
+}
+
+// receivingBlocks reports whether watchers get new blocks from subscription and don't need to poll.
+func (cmd *Command) receivingBlocks() bool {
+	if atomic.LoadInt32(&cmd.subscribed) == 0 {
+		return false
+	}
+
+	return time.Since(time.Unix(0, atomic.LoadInt64(&cmd.lastEvent))) < cmd.stallTimeout()
+}
 
-	return false, nil
+func (cmd *Command) stallTimeout() time.Duration {
+	return 2 * time.Duration(cmd.config.Minter.Sleep) * time.Second
 }
 
-func (cmd *Command) cleanupMissedBlocks() {
-	if len(cmd.missedBlocks) == 0 {
-		return
+func (cmd *Command) checkNodeHealth(ctx context.Context) {
+	interval := cmd.config.Minter.Failover.HealthCheckInterval
+
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
 	}
 
-	var temp []int
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cmd.minter.CheckHealth()
+
+			active := cmd.minter.Endpoint()
+
+			for _, h := range cmd.minter.Health() {
+				if h.Open {
+					cmd.log.WithField("endpoint", h.URL).
+						WithField("open_until", h.OpenUntil).
+						WithField("error", h.LastError).
+						Warnln("Node API is ejected")
+				}
 
-	for _, height := range cmd.missedBlocks {
-		if cmd.lastBlock-height < cmd.config.Minter.MissedBlockRemoveAfter {
-			temp = append(temp, height)
+				if cmd.prometheus != nil {
+					cmd.prometheus.SetNodeEndpointHealth(h.URL, !h.Open, h.Height, h.ErrorRate, h.URL == active)
+				}
+			}
 		}
 	}
+}
 
-	cmd.missedBlocks = temp
+func (cmd *Command) lastBlockHeight() (int, error) {
+	status, err := cmd.minter.Status()
 
-	if cmd.prometheus != nil {
-		cmd.prometheus.SetBlocksMissedCurrent(len(cmd.missedBlocks))
+	if err != nil {
+		return 0, err
 	}
-}
 
-func (cmd *Command) newLogEntry(height int) *logrus.Entry {
-	return cmd.log.WithField("height", height).WithField("missed", len(cmd.missedBlocks))
+	return status.LatestBlockHeight, nil
 }
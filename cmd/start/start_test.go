@@ -0,0 +1,34 @@
+package start
+
+import (
+	"minter-sentinel/config"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCommand_ReceivingBlocks(t *testing.T) {
+	cmd := &Command{config: &config.Config{Minter: config.Minter{Sleep: 5}}}
+
+	tests := []struct {
+		name       string
+		subscribed int32
+		lastEvent  time.Duration
+		receiving  bool
+	}{
+		{"not subscribed", 0, time.Second, false},
+		{"fresh event", 1, time.Second, true},
+		{"stalled", 1, 11 * time.Second, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&cmd.subscribed, tt.subscribed)
+			atomic.StoreInt64(&cmd.lastEvent, time.Now().Add(-tt.lastEvent).UnixNano())
+
+			if receiving := cmd.receivingBlocks(); receiving != tt.receiving {
+				t.Fatalf("expected %v, got %v", tt.receiving, receiving)
+			}
+		})
+	}
+}
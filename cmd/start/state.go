@@ -0,0 +1,65 @@
+package start
+
+import "minter-sentinel/services/state"
+
+// restoreState resumes watching from the last saved height, so blocks produced while sentinel was down are checked too.
+// No more than missed_block_remove_after blocks are replayed: older misses can't count towards the threshold anymore,
+// and replaying them after a long outage could turn off a validator that is signing blocks now.
+func (w *watcher) restoreState() {
+	if w.cmd.state == nil {
+		return
+	}
+
+	saved := w.cmd.state.Validator(w.validator.PublicKey)
+
+	if saved == nil {
+		w.newLogEntry(w.lastBlock).WithField("path", w.cmd.state.Path()).Println("No saved state found. Starting from the latest block")
+		return
+	}
+
+	entry := w.newLogEntry(w.lastBlock).
+		WithField("path", w.cmd.state.Path()).
+		WithField("saved_height", saved.LastBlock).
+		WithField("saved_missed", len(saved.MissedBlocks)).
+		WithField("updated_at", saved.UpdatedAt)
+
+	if saved.LastBlock > w.lastBlock {
+		entry.Warnln("Saved height is ahead of the chain. Starting from the latest block")
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.missedBlocks = saved.MissedBlocks
+
+	if limit := w.validator.MissedBlockRemoveAfter; limit > 0 && saved.LastBlock > 0 && w.lastBlock-saved.LastBlock > limit {
+		entry.WithField("skipped", w.lastBlock-saved.LastBlock-limit).
+			WithField("replayed", limit).
+			Warnln("Saved height is too old. Replaying only the last missed_block_remove_after blocks")
+
+		w.missedBlocks = nil
+		w.lastBlock -= limit
+	} else if saved.LastBlock > 0 {
+		entry.WithField("skipped", w.lastBlock-saved.LastBlock).Println("Resuming from saved state")
+
+		w.lastBlock = saved.LastBlock
+	}
+
+	w.policy.Restore(w.missedBlocks, w.lastBlock)
+}
+
+func (w *watcher) saveState() {
+	if w.cmd.state == nil {
+		return
+	}
+
+	err := w.cmd.state.SaveValidator(w.validator.PublicKey, state.Validator{
+		LastBlock:    w.lastBlock,
+		MissedBlocks: w.missedBlocks,
+	})
+
+	if err != nil {
+		w.newLogEntry(w.lastBlock).WithError(err).Errorln("Failed to save state")
+	}
+}
@@ -0,0 +1,59 @@
+package start
+
+import (
+	"io/ioutil"
+	"minter-sentinel/config"
+	"minter-sentinel/services/state"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWatcher_RestoreState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "minter-sentinel")
+
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name      string
+		saved     state.Validator
+		lastBlock int
+		missed    int
+	}{
+		{"recent", state.Validator{LastBlock: 990, MissedBlocks: []int{985, 989}}, 990, 2},
+		{"too old", state.Validator{LastBlock: 500, MissedBlocks: []int{495, 499}}, 1000 - 24, 0},
+		{"ahead of chain", state.Validator{LastBlock: 1010, MissedBlocks: []int{1005}}, 1000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, err := state.New(filepath.Join(dir, tt.name+".json"), true)
+
+			if err != nil {
+				t.Fatalf("failed to create state service: %s", err)
+			}
+
+			if err := svc.SaveValidator(testPublicKey, tt.saved); err != nil {
+				t.Fatalf("failed to save state: %s", err)
+			}
+
+			w, _ := newTestWatcher(t, newFakeNode(t), config.Minter{})
+			w.cmd.state = svc
+			w.lastBlock = 1000
+
+			w.restoreState()
+
+			if w.lastBlock != tt.lastBlock {
+				t.Fatalf("wrong last block: expected %d, got %d", tt.lastBlock, w.lastBlock)
+			}
+
+			if len(w.missedBlocks) != tt.missed {
+				t.Fatalf("wrong missed blocks: expected %d, got %v", tt.missed, w.missedBlocks)
+			}
+		})
+	}
+}
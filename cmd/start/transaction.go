@@ -0,0 +1,139 @@
+package start
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"minter-sentinel/services/minter/node"
+	"minter-sentinel/services/notifier"
+	"time"
+
+	"github.com/MinterTeam/minter-go-sdk/v2/transaction"
+)
+
+const (
+	defaultRebroadcastAfter = 3
+	defaultMaxRebroadcasts  = 3
+	defaultConfirmTimeout   = 120
+)
+
+var TransactionNotIncluded = errors.New("transaction is not included in a block")
+
+// confirmTransaction polls Node API until transaction is committed and rebroadcasts it through other
+// endpoints if it is not included within `rebroadcast_after` blocks. It gives up after `timeout` seconds,
+// so unavailable Node APIs can't block turning off forever.
+func (w *watcher) confirmTransaction(ctx context.Context, tx string, sent *node.SendTransactionResponse) error {
+	hash := sent.Hash
+
+	if len(hash) == 0 {
+		decoded, err := transaction.Decode(tx)
+
+		if err != nil {
+			return err
+		}
+
+		if hash, err = decoded.Hash(); err != nil {
+			return err
+		}
+	}
+
+	rebroadcastAfter := w.cmd.config.Minter.Confirmation.RebroadcastAfter
+
+	if rebroadcastAfter <= 0 {
+		rebroadcastAfter = defaultRebroadcastAfter
+	}
+
+	maxRebroadcasts := w.cmd.config.Minter.Confirmation.MaxRebroadcasts
+
+	if maxRebroadcasts <= 0 {
+		maxRebroadcasts = defaultMaxRebroadcasts
+	}
+
+	timeout := w.cmd.config.Minter.Confirmation.Timeout
+
+	if timeout <= 0 {
+		timeout = defaultConfirmTimeout
+	}
+
+	poll := time.Duration(w.cmd.config.Minter.Sleep) * time.Second
+
+	if poll < time.Second {
+		poll = time.Second
+	}
+
+	entry := w.newLogEntry(w.lastBlock).WithField("hash", hash)
+	entry.Println("Waiting for transaction to be included in a block")
+
+	sentAt, _ := w.cmd.lastBlockHeight()
+	rebroadcasts := 0
+	until := time.Now().Add(time.Duration(timeout) * time.Second)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(poll):
+		}
+
+		if time.Now().After(until) {
+			return fmt.Errorf("%w: %s within %d sec.", TransactionNotIncluded, hash, timeout)
+		}
+
+		t, err := w.cmd.minter.GetTransaction(hash)
+
+		if err == nil {
+			if t.Code != 0 {
+				return fmt.Errorf("transaction %s failed in block %d: [%d] %s", hash, t.Height, t.Code, t.Log)
+			}
+
+			entry.WithField("tx_height", t.Height).Println("Transaction included in a block")
+
+			w.notify(notifier.Info, fmt.Sprintf("✅ Transaction %s included in block %d", hash, t.Height))
+
+			return nil
+		}
+
+		if _, ok := err.(*node.TransactionNotFound); !ok {
+			entry.WithError(err).Warnln("Failed to get transaction")
+			continue
+		}
+
+		height, err := w.cmd.lastBlockHeight()
+
+		if err != nil {
+			continue
+		}
+
+		if sentAt == 0 {
+			sentAt = height
+			continue
+		}
+
+		if height-sentAt < rebroadcastAfter {
+			continue
+		}
+
+		if rebroadcasts >= maxRebroadcasts {
+			return fmt.Errorf("%w: %s after %d rebroadcasts", TransactionNotIncluded, hash, rebroadcasts)
+		}
+
+		rebroadcasts++
+		sentAt = height
+
+		entry.WithField("rebroadcast", rebroadcasts).Warnf("Transaction is not included within %d blocks. Rebroadcasting", rebroadcastAfter)
+
+		w.notify(notifier.Warning, fmt.Sprintf("⚠️ Transaction %s is not included within %d blocks. Rebroadcasting [%d/%d]", hash, rebroadcastAfter, rebroadcasts, maxRebroadcasts))
+
+		for _, result := range w.cmd.minter.BroadcastTransaction(tx, sent.Endpoint) {
+			e := entry.WithField("node", result.Endpoint)
+
+			if result.Err != nil {
+				e.WithError(result.Err).Warnln("Failed to rebroadcast transaction")
+			} else if result.Response.Error != nil {
+				e.Warnf("Failed to rebroadcast transaction: [%d] %s", result.Response.Error.Code, result.Response.Error.Message)
+			} else {
+				e.Println("Transaction rebroadcasted")
+			}
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package start
+
+import (
+	"errors"
+	"fmt"
+	"minter-sentinel/services/keystore"
+	"minter-sentinel/services/minter/node"
+	"minter-sentinel/services/notifier"
+	"strings"
+
+	"github.com/MinterTeam/minter-go-sdk/v2/transaction"
+)
+
+const (
+	validationWarn   = "warn"
+	validationStrict = "strict"
+)
+
+// validateTransactionOff decodes configured transaction_off, so a stale or wrong transaction
+// is found out at start rather than in an emergency.
+func (w *watcher) validateTransactionOff(controlAddress string) error {
+	if len(w.validator.TransactionOff) == 0 {
+		return nil
+	}
+
+	problems := w.checkTransactionOff(controlAddress)
+
+	if len(problems) == 0 {
+		w.newLogEntry(w.lastBlock).Println("transaction_off is valid")
+		return nil
+	}
+
+	err := errors.New("invalid transaction_off: " + strings.Join(problems, "; "))
+
+	if w.cmd.config.Minter.TransactionOffValidation == validationStrict {
+		return err
+	}
+
+	w.newLogEntry(w.lastBlock).Warnln(err)
+	w.notify(notifier.Warning, fmt.Sprintf("⚠️ %s", err))
+
+	return nil
+}
+
+func (w *watcher) checkTransactionOff(controlAddress string) []string {
+	tx, err := node.DecodeTransaction(w.validator.TransactionOff)
+
+	if err != nil {
+		return []string{fmt.Sprintf("failed to decode: %s", err)}
+	}
+
+	var problems []string
+
+	if tx.Type != transaction.TypeSetCandidateOffline {
+		problems = append(problems, fmt.Sprintf("transaction type is %d, not SetCandidateOff", tx.Type))
+	}
+
+	if tx.PublicKey != w.validator.PublicKey {
+		problems = append(problems, fmt.Sprintf("public key is %s", tx.PublicKey))
+	}
+
+	chainID := transaction.MainNetChainID
+
+	if w.cmd.config.Minter.Testnet {
+		chainID = transaction.TestNetChainID
+	}
+
+	if tx.ChainID != chainID {
+		problems = append(problems, fmt.Sprintf("chain id is %d, expected %d", tx.ChainID, chainID))
+	}
+
+	if tx.Sender != controlAddress {
+		problems = append(problems, fmt.Sprintf("sender is %s, control address is %s", tx.Sender, controlAddress))
+	}
+
+	address, err := w.cmd.minter.GetAddress(tx.Sender)
+
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("failed to get nonce of %s: %s", tx.Sender, err))
+	} else if tx.Nonce != address.TransactionCount+1 {
+		problems = append(problems, fmt.Sprintf("nonce is %d, expected %d", tx.Nonce, address.TransactionCount+1))
+	}
+
+	return problems
+}
+
+// validateKeys checks that configured seeds or keystore can sign for the candidate control address.
+// For multisig control address combined weight of the keys must reach the threshold.
+// Keys that don't control the candidate are dropped, so transaction is signed only by the control address or multisig owners.
+func (w *watcher) validateKeys(controlAddress string) error {
+	addresses := w.keyAddresses()
+
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	entry := w.newLogEntry(w.lastBlock).
+		WithField("control_address", controlAddress).
+		WithField("addresses", addresses)
+
+	controlling, err := w.checkKeys(controlAddress, addresses)
+
+	if err == nil {
+		if ignored := w.keepKeys(controlling); len(ignored) > 0 {
+			entry.WithField("ignored", ignored).Warnln("Keys that don't control the candidate are ignored")
+		}
+
+		entry.Println("Keys control the candidate")
+		return nil
+	}
+
+	if len(w.validator.TransactionOff) == 0 {
+		return err
+	}
+
+	w.keys = nil
+	w.keysRejected = true
+
+	entry.WithError(err).Warnln("Keys don't control the candidate. Signing is disabled, transaction_off will be used")
+	w.notify(notifier.Warning, fmt.Sprintf("⚠️ %s. transaction_off will be used", err))
+
+	return nil
+}
+
+// checkKeys returns addresses of the keys that can sign for control address: the control address itself,
+// or owners of multisig control address.
+func (w *watcher) checkKeys(controlAddress string, addresses []string) ([]string, error) {
+	address, err := w.cmd.minter.GetAddress(controlAddress)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if address.Multisig == nil {
+		for _, a := range addresses {
+			if a == controlAddress {
+				return []string{a}, nil
+			}
+		}
+
+		return nil, fmt.Errorf("seeds don't control the candidate: control address is %s, seeds are of %s", controlAddress, strings.Join(addresses, ", "))
+	}
+
+	var owners []string
+
+	for _, a := range addresses {
+		for _, owner := range address.Multisig.Addresses {
+			if a == owner {
+				owners = append(owners, a)
+				break
+			}
+		}
+	}
+
+	weight, err := address.Multisig.Weight(owners)
+
+	if err != nil {
+		return nil, err
+	}
+
+	w.newLogEntry(w.lastBlock).
+		WithField("weight", weight).
+		WithField("threshold", address.Multisig.Threshold).
+		Println("Multisig control address")
+
+	if weight < address.Multisig.Threshold {
+		return nil, fmt.Errorf("seeds don't control the candidate: weight of seeds in multisig %s is %d, threshold is %d", controlAddress, weight, address.Multisig.Threshold)
+	}
+
+	return owners, nil
+}
+
+// keepKeys keeps only keys of the given addresses and returns addresses of the dropped ones.
+func (w *watcher) keepKeys(addresses []string) []string {
+	keep := map[string]bool{}
+
+	for _, a := range addresses {
+		keep[a] = true
+	}
+
+	var keys []keystore.Key
+	var dropped []string
+
+	for _, k := range w.keys {
+		if keep[k.Address] {
+			keys = append(keys, k)
+		} else {
+			dropped = append(dropped, k.Address)
+		}
+	}
+
+	w.keys = keys
+
+	return dropped
+}
+
+func (w *watcher) keyAddresses() []string {
+	var addresses []string
+
+	for _, k := range w.keys {
+		addresses = append(addresses, k.Address)
+	}
+
+	return addresses
+}
@@ -0,0 +1,83 @@
+package start
+
+import (
+	"minter-sentinel/config"
+	"minter-sentinel/services/keystore"
+	"minter-sentinel/services/minter/node"
+	"strings"
+	"testing"
+
+	"github.com/MinterTeam/minter-go-sdk/v2/wallet"
+)
+
+func newTestKeys(t *testing.T, count int) []keystore.Key {
+	var keys []keystore.Key
+
+	for i := 0; i < count; i++ {
+		wal, err := wallet.New()
+
+		if err != nil {
+			t.Fatalf("failed to create wallet: %s", err)
+		}
+
+		keys = append(keys, keystore.Key{Address: wal.Address, PrivateKey: wal.PrivateKey})
+	}
+
+	return keys
+}
+
+func TestWatcher_ValidateKeys(t *testing.T) {
+	keys := newTestKeys(t, 3)
+	multisig := "Mx00000000000000000000000000000000000000ff"
+
+	tests := []struct {
+		name           string
+		controlAddress string
+		multisig       *node.Multisig
+		keys           []keystore.Key
+		kept           []string
+		shouldFail     bool
+	}{
+		{"single key", keys[0].Address, nil, keys[:1], []string{keys[0].Address}, false},
+		{"extra seed of regular address", keys[0].Address, nil, keys[:2], []string{keys[0].Address}, false},
+		{"wrong seed", keys[0].Address, nil, keys[1:2], nil, true},
+		{
+			"extra seed of multisig", multisig,
+			&node.Multisig{Threshold: 2, Weights: []string{"1", "1"}, Addresses: []string{keys[0].Address, keys[1].Address}},
+			keys, []string{keys[0].Address, keys[1].Address}, false,
+		},
+		{
+			"multisig threshold not reached", multisig,
+			&node.Multisig{Threshold: 2, Weights: []string{"1", "1"}, Addresses: []string{keys[0].Address, keys[1].Address}},
+			[]keystore.Key{keys[0], keys[2]}, nil, true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newFakeNode(t)
+			n.addresses[tt.controlAddress] = node.GetAddressResponse{Multisig: tt.multisig}
+
+			w, _ := newTestWatcher(t, n, config.Minter{})
+			w.keys = tt.keys
+
+			err := w.validateKeys(tt.controlAddress)
+
+			if tt.shouldFail {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if kept := w.keyAddresses(); strings.Join(kept, ",") != strings.Join(tt.kept, ",") {
+				t.Fatalf("wrong keys kept: expected %v, got %v", tt.kept, kept)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package start
+
+import (
+	"fmt"
+	"minter-sentinel/services/control"
+	"minter-sentinel/services/notifier"
+)
+
+type blockOutcome int
+
+const (
+	blockSigned blockOutcome = iota
+	blockMissed
+	blockNotInSet
+	// block is missed by too many validators to be counted
+	blockExcluded
+)
+
+// leaveValidatorSet moves watcher to waiting state when validator is not in the validator set of the block.
+// Such blocks are not counted as missed, because turning off a candidate that doesn't validate makes no sense.
+func (w *watcher) leaveValidatorSet(height int) {
+	if w.outOfSet {
+		w.newLogEntry(height).Debugln("Validator is not in the validator set")
+		return
+	}
+
+	w.mu.Lock()
+	w.outOfSet = true
+	w.mu.Unlock()
+
+	w.setState(control.StateWaiting)
+
+	w.newLogEntry(height).Errorln("Validator is not in the validator set. Waiting until it's back")
+
+	w.notify(notifier.Critical, fmt.Sprintf("🚨 Validator is not in the validator set since block %d. Blocks are not counted as missed until it's back", height))
+}
+
+func (w *watcher) returnToValidatorSet(height int) {
+	if !w.outOfSet {
+		return
+	}
+
+	w.mu.Lock()
+	w.outOfSet = false
+	w.mu.Unlock()
+
+	w.setState(control.StateWatching)
+
+	w.newLogEntry(height).Println("Validator is back in the validator set")
+
+	w.notify(notifier.Info, fmt.Sprintf("✅ Validator is back in the validator set since block %d", height))
+}
@@ -0,0 +1,55 @@
+package start
+
+import (
+	"context"
+	"fmt"
+	"minter-sentinel/services/minter/node"
+	"time"
+)
+
+const (
+	defaultVerificationDeadline = 60
+	defaultVerificationRetries  = 2
+)
+
+// verifyOffline polls candidate until it's no longer online or the deadline since transaction is included passes.
+func (w *watcher) verifyOffline(ctx context.Context, started time.Time) error {
+	deadline := w.cmd.config.Minter.Verification.Deadline
+
+	if deadline <= 0 {
+		deadline = defaultVerificationDeadline
+	}
+
+	poll := time.Duration(w.cmd.config.Minter.Sleep) * time.Second
+
+	if poll < time.Second {
+		poll = time.Second
+	}
+
+	until := started.Add(time.Duration(deadline) * time.Second)
+
+	for {
+		candidate, err := w.cmd.minter.GetCandidate(w.validator.PublicKey)
+
+		if err != nil {
+			w.newLogEntry(w.lastBlock).WithError(err).Warnln("Failed to get candidate")
+		} else if candidate.Status != node.CandidateStatusOnline {
+			w.newLogEntry(w.lastBlock).
+				WithField("status", candidate.Status).
+				WithField("took", time.Since(started)).
+				Println("Candidate is offline")
+
+			return nil
+		}
+
+		if time.Now().After(until) {
+			return fmt.Errorf("candidate is still online %d sec. after turn off", deadline)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(poll):
+		}
+	}
+}
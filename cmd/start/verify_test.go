@@ -0,0 +1,93 @@
+package start
+
+import (
+	"context"
+	"minter-sentinel/config"
+	"minter-sentinel/services/minter/node"
+	"testing"
+	"time"
+)
+
+func TestWatcher_VerifyOffline(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          int
+		candidateErrors int
+		goesOffline     bool
+		shouldFail      bool
+	}{
+		{"confirmed off", node.CandidateStatusOffline, 0, false, false},
+		{"goes off while polling", node.CandidateStatusOnline, 0, true, false},
+		{"still online", node.CandidateStatusOnline, 0, false, true},
+		{"node error then off", node.CandidateStatusOffline, 1, false, false},
+		{"node error", node.CandidateStatusOffline, 1000, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newFakeNode(t)
+			n.candidate.Status = tt.status
+			n.candidateErrors = tt.candidateErrors
+
+			w, _ := newTestWatcher(t, n, config.Minter{Verification: config.Verification{Deadline: 1}})
+
+			if tt.goesOffline {
+				time.AfterFunc(500*time.Millisecond, func() {
+					n.update(func(n *fakeNode) {
+						n.candidate.Status = node.CandidateStatusOffline
+					})
+				})
+			}
+
+			err := w.verifyOffline(context.Background(), time.Now())
+
+			if tt.shouldFail && err == nil {
+				t.Fatalf("expected error")
+			}
+
+			if !tt.shouldFail && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestWatcher_TurnOff(t *testing.T) {
+	keys := newTestKeys(t, 1)
+
+	tests := []struct {
+		name       string
+		offline    bool
+		shouldFail bool
+		message    string
+	}{
+		{"confirmed off", true, false, "Masternode is off. Candidate went offline"},
+		{"still online", false, true, "Giving up after 1 retries"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newFakeNode(t)
+			n.candidate.ControlAddress = keys[0].Address
+			n.offOnInclude = tt.offline
+
+			w, r := newTestWatcher(t, n, config.Minter{Verification: config.Verification{Deadline: 1, Retries: 1}})
+			w.keys = keys
+
+			err := w.turnOff(context.Background())
+			w.cmd.wg.Wait()
+
+			if tt.shouldFail && err == nil {
+				t.Fatalf("expected error")
+			}
+
+			if !tt.shouldFail && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !r.contains(tt.message) {
+				t.Fatalf("expected %q notification, got %v", tt.message, r.texts())
+			}
+		})
+	}
+}
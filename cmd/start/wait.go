@@ -0,0 +1,159 @@
+package start
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"minter-sentinel/services/control"
+	"minter-sentinel/services/minter/node"
+	"minter-sentinel/services/notifier"
+	"time"
+)
+
+var (
+	CandidateJailed       = errors.New("candidate is jailed")
+	CandidateNotValidator = errors.New("candidate is not a validator yet")
+	CandidateNotOnline    = errors.New("candidate is not online")
+	LastBlockNotSigned    = errors.New("last block is not signed by the validator, start watcher when validator starts signing blocks")
+)
+
+// approximate block time used to estimate time until unjail
+const blockTime = 5
+
+// checkReady returns the condition that prevents candidate from being watched, or nil if it's online and signing blocks.
+func (w *watcher) checkReady(candidate *node.CandidateResponse, height int) error {
+	if candidate.JailedUntil > 0 && candidate.JailedUntil > height {
+		return fmt.Errorf(
+			"%w until block %d, current block: %d, blocks until unjail: %d (approx. %d sec.)",
+			CandidateJailed,
+			candidate.JailedUntil,
+			height,
+			candidate.JailedUntil-height,
+			(candidate.JailedUntil-height)*blockTime,
+		)
+	}
+
+	if !candidate.Validator {
+		return CandidateNotValidator
+	}
+
+	if candidate.Status != node.CandidateStatusOnline {
+		return CandidateNotOnline
+	}
+
+	signed, err := w.isSigned(height)
+
+	if err != nil {
+		return err
+	}
+
+	if !signed {
+		return LastBlockNotSigned
+	}
+
+	return nil
+}
+
+// waitUntilReady polls the candidate until it's online and signing blocks, notifying when blocking condition changes.
+// Returns turnOff if manual turn off is requested while waiting, and false ok if watcher is stopped.
+func (w *watcher) waitUntilReady(ctx context.Context) (turnOff bool, ok bool) {
+	interval := w.cmd.config.Minter.Rearm.Interval
+
+	if interval <= 0 {
+		interval = defaultRearmInterval
+	}
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	w.setState(control.StateWaiting)
+
+	var reported error
+
+	for {
+		height, err := w.cmd.lastBlockHeight()
+
+		if err == nil {
+			err = w.checkCandidateReady(height)
+		}
+
+		switch {
+		case err == nil:
+			w.startWatching(height)
+			return false, true
+		case readyCondition(err) != "":
+			w.reportNotReady(height, err, reported)
+			reported = err
+		default:
+			w.newLogEntry(height).WithError(err).Warnln("Failed to check if candidate is ready")
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, false
+		case <-w.resetRequests:
+			w.newLogEntry(height).Println("Reset requested while waiting for candidate. Missed blocks are not counted yet")
+			w.notify(notifier.Info, "♻️ Nothing to reset: missed blocks are not counted while waiting for masternode")
+		case <-w.turnOffRequests:
+			w.newLogEntry(height).Warnln("Manual turn off requested while waiting for candidate")
+			w.notify(notifier.Critical, "🚨 Manual turn off requested")
+
+			return true, true
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *watcher) checkCandidateReady(height int) error {
+	candidate, err := w.cmd.minter.GetCandidate(w.validator.PublicKey)
+
+	if err != nil {
+		return err
+	}
+
+	return w.checkReady(candidate, height)
+}
+
+func (w *watcher) startWatching(height int) {
+	w.mu.Lock()
+	w.lastBlock = height
+	w.missedBlocks = nil
+	w.notReady = nil
+	w.mu.Unlock()
+
+	w.policy.Reset()
+	w.escalation = -1
+
+	w.saveState()
+
+	w.newLogEntry(height).Println("Candidate is ready. Watching started")
+	w.notify(notifier.Info, "✅ Masternode is online and signing blocks. Watching started")
+}
+
+func (w *watcher) reportNotReady(height int, err error, reported error) {
+	entry := w.newLogEntry(height).WithError(err)
+
+	if reported != nil && readyCondition(err) == readyCondition(reported) {
+		if errors.Is(err, CandidateJailed) {
+			entry.Println("Candidate is jailed. Waiting")
+		}
+
+		return
+	}
+
+	entry.Warnln("Candidate is not ready. Waiting")
+
+	w.notify(notifier.Warning, fmt.Sprintf("⏳ Waiting for masternode: %s", err))
+}
+
+// readyCondition strips details like unjail countdown, so only changes of the condition itself are notified.
+// Returns empty string for errors that are not readiness conditions, e.g. Node API errors.
+func readyCondition(err error) string {
+	for _, condition := range []error{CandidateJailed, CandidateNotValidator, CandidateNotOnline, LastBlockNotSigned, NoValidatorsSignedYet} {
+		if errors.Is(err, condition) {
+			return condition.Error()
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,167 @@
+package start
+
+import (
+	"context"
+	"minter-sentinel/config"
+	"minter-sentinel/services/control"
+	"minter-sentinel/services/minter/node"
+	"testing"
+	"time"
+)
+
+func TestWatcher_WaitUntilReady(t *testing.T) {
+	tests := []struct {
+		name      string
+		notReady  func(n *fakeNode)
+		condition string
+	}{
+		{"not validator", func(n *fakeNode) { n.candidate.Validator = false }, CandidateNotValidator.Error()},
+		{"jailed", func(n *fakeNode) { n.candidate.JailedUntil = n.height + 10 }, CandidateJailed.Error()},
+		{"offline", func(n *fakeNode) { n.candidate.Status = node.CandidateStatusOffline }, CandidateNotOnline.Error()},
+		{"not signing", func(n *fakeNode) { n.missed[n.height] = true }, LastBlockNotSigned.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newFakeNode(t)
+			n.update(tt.notReady)
+
+			w, r := newTestWatcher(t, n, config.Minter{Rearm: config.Rearm{Interval: 1}})
+
+			type result struct{ turnOff, ok bool }
+
+			done := make(chan result, 1)
+
+			go func() {
+				turnOff, ok := w.waitUntilReady(context.Background())
+				done <- result{turnOff, ok}
+			}()
+
+			waitFor(t, "waiting notification", func() bool { return r.contains("Waiting for masternode: " + tt.condition) })
+
+			if w.snapshot().State != control.StateWaiting {
+				t.Fatalf("wrong state: expected %s, got %s", control.StateWaiting, w.snapshot().State)
+			}
+
+			// the next block keeps the same condition, e.g. shorter unjail countdown, and is not notified again
+			n.update(func(n *fakeNode) {
+				n.height++
+				n.missed[n.height] = n.missed[n.height-1]
+			})
+
+			time.Sleep(1500 * time.Millisecond)
+
+			if count := countMessages(r, "Waiting for masternode"); count != 1 {
+				t.Fatalf("condition should be notified once, got %d: %v", count, r.texts())
+			}
+
+			n.update(func(n *fakeNode) {
+				n.candidate = node.CandidateResponse{ControlAddress: n.candidate.ControlAddress, Status: node.CandidateStatusOnline, Validator: true}
+				n.height++
+			})
+
+			select {
+			case res := <-done:
+				if res.turnOff || !res.ok {
+					t.Fatalf("watcher should start watching, got turn off %v, ok %v", res.turnOff, res.ok)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for candidate to be ready")
+			}
+
+			w.cmd.wg.Wait()
+
+			if !r.contains("Masternode is online and signing blocks. Watching started") {
+				t.Fatalf("expected watching started notification, got %v", r.texts())
+			}
+
+			if w.lastBlock != 102 || w.notReady != nil {
+				t.Fatalf("watching should start from block 102, got %d (%v)", w.lastBlock, w.notReady)
+			}
+		})
+	}
+}
+
+func TestWatcher_WaitUntilReady_Requests(t *testing.T) {
+	n := newFakeNode(t)
+	n.candidate.Status = node.CandidateStatusOffline
+
+	w, r := newTestWatcher(t, n, config.Minter{Rearm: config.Rearm{Interval: 60}})
+
+	done := make(chan bool, 1)
+
+	go func() {
+		turnOff, ok := w.waitUntilReady(context.Background())
+		done <- turnOff && ok
+	}()
+
+	waitFor(t, "reset to be accepted", func() bool { return w.requestReset() == nil })
+	waitFor(t, "reset notification", func() bool { return r.contains("Nothing to reset") })
+
+	select {
+	case <-done:
+		t.Fatalf("reset should not stop waiting")
+	default:
+	}
+
+	if err := w.requestTurnOff(); err != nil {
+		t.Fatalf("failed to request turn off: %s", err)
+	}
+
+	select {
+	case turnOff := <-done:
+		if !turnOff {
+			t.Fatalf("manual turn off should be returned")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for manual turn off")
+	}
+
+	w.cmd.wg.Wait()
+
+	if !r.contains("Manual turn off requested") {
+		t.Fatalf("expected manual turn off notification, got %v", r.texts())
+	}
+}
+
+// TestWatcher_Run_WaitTurnOff checks that manual turn off requested while waiting in --wait mode runs the turn off flow.
+func TestWatcher_Run_WaitTurnOff(t *testing.T) {
+	n := newFakeNode(t)
+	n.candidate.Status = node.CandidateStatusOffline
+
+	w, r := newTestWatcher(t, n, config.Minter{Rearm: config.Rearm{Interval: 60}})
+	w.notReady = CandidateNotOnline
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- w.run(ctx)
+	}()
+
+	waitFor(t, "turn off to be accepted", func() bool { return w.requestTurnOff() == nil })
+	waitFor(t, "turn off", func() bool { return w.snapshot().State == control.StateOff })
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for watcher to stop")
+	}
+
+	w.cmd.wg.Wait()
+
+	if !r.contains("Masternode is off") {
+		t.Fatalf("expected masternode off notification, got %v", r.texts())
+	}
+
+	if w.snapshot().State != control.StateStopped {
+		t.Fatalf("wrong state: expected %s, got %s", control.StateStopped, w.snapshot().State)
+	}
+}
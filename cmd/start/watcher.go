@@ -0,0 +1,742 @@
+package start
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"minter-sentinel/config"
+	"minter-sentinel/services/control"
+	"minter-sentinel/services/keystore"
+	"minter-sentinel/services/minter/node"
+	"minter-sentinel/services/notifier"
+	"minter-sentinel/services/policy"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type watcher struct {
+	cmd       *Command
+	validator config.Validator
+	notifier  *notifier.Service
+
+	mu             sync.RWMutex
+	missedBlocks   []int
+	lastBlock      int
+	controlAddress string
+	keys           []keystore.Key
+	keysRejected   bool
+	ladder         []rung
+	escalation     int
+	policy         policy.Policy
+	verdict        policy.Verdict
+	presigned      *presigned
+	lowBalance     bool
+	lowGasPrice    bool
+	balanceWarned  bool
+	outOfSet       bool
+	notReady       error
+	networkMissed  float64
+	excludedBlocks int
+	paused         bool
+	state          string
+	errors         checkErrors
+
+	newBlocks       chan node.NewBlockEvent
+	turnOffRequests chan struct{}
+	resetRequests   chan struct{}
+}
+
+func newWatcher(cmd *Command, validator config.Validator, notifier *notifier.Service) *watcher {
+	return &watcher{
+		cmd:        cmd,
+		validator:  validator,
+		notifier:   notifier,
+		state:      control.StateStarting,
+		escalation: -1,
+
+		newBlocks:       make(chan node.NewBlockEvent, 1),
+		turnOffRequests: make(chan struct{}, 1),
+		resetRequests:   make(chan struct{}, 1),
+	}
+}
+
+// prepare checks that candidate is ready to be watched starting from the given height.
+// In wait mode a candidate that is not ready yet doesn't fail the start, watcher waits for it in run instead.
+func (w *watcher) prepare(lastBlock int) error {
+	candidate, err := w.cmd.minter.GetCandidate(w.validator.PublicKey)
+
+	if err != nil {
+		return err
+	}
+
+	ready := w.checkReady(candidate, lastBlock)
+
+	if ready != nil && !w.cmd.wait {
+		return ready
+	}
+
+	w.mu.Lock()
+	w.lastBlock = lastBlock
+	w.controlAddress = candidate.ControlAddress
+	w.notReady = ready
+	w.mu.Unlock()
+
+	if err := w.validateTransactionOff(candidate.ControlAddress); err != nil {
+		return err
+	}
+
+	if err := w.validateKeys(candidate.ControlAddress); err != nil {
+		return err
+	}
+
+	if ready != nil {
+		return nil
+	}
+
+	w.restoreState()
+
+	return nil
+}
+
+func (w *watcher) run(ctx context.Context) error {
+	w.newLogEntry(w.lastBlock).
+		WithField("missed_blocks_threshold", w.validator.MissedBlocksThreshold).
+		WithField("sleep", w.cmd.config.Minter.Sleep).
+		WithField("subscribe", w.cmd.config.Minter.Subscribe).
+		WithField("missed_block_remove_after", w.validator.MissedBlockRemoveAfter).
+		WithField("control_address", w.controlAddress).
+		Println("Watcher started")
+
+	backgroundCtx, cancelBackground := context.WithCancel(ctx)
+	defer cancelBackground()
+
+	go w.presign(backgroundCtx)
+	go w.monitorBalance(backgroundCtx)
+
+	turnOff := false
+
+	if w.notReady != nil {
+		var ok bool
+
+		if turnOff, ok = w.waitUntilReady(ctx); !ok {
+			w.setState(control.StateStopped)
+			return nil
+		}
+	}
+
+	for {
+		if !turnOff && !w.watch(ctx) {
+			w.setState(control.StateStopped)
+			return nil
+		}
+
+		turnOff = false
+
+		w.setState(control.StateTurningOff)
+
+		if err := w.turnOff(ctx); err != nil {
+			w.setState(control.StateFailed)
+
+			return err
+		}
+
+		w.setState(control.StateOff)
+
+		if !w.waitUntilOnline(ctx) {
+			w.setState(control.StateStopped)
+			return nil
+		}
+	}
+}
+
+// watch checks blocks until masternode has to be turned off. Returns false if watcher is stopped.
+func (w *watcher) watch(ctx context.Context) bool {
+	ticker := time.NewTicker(time.Duration(w.cmd.config.Minter.Sleep) * time.Second)
+	defer ticker.Stop()
+
+	w.setState(control.StateWatching)
+
+	turnOff := false
+
+	for !turnOff {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-w.turnOffRequests:
+			w.newLogEntry(w.lastBlock).Warnln("Manual turn off requested")
+			w.notify(notifier.Critical, "🚨 Manual turn off requested")
+
+			turnOff = true
+		case <-w.resetRequests:
+			w.resetMissedBlocks()
+		case <-ticker.C:
+			if w.cmd.receivingBlocks() {
+				continue
+			}
+
+			for !turnOff {
+				var advanced bool
+
+				if advanced, turnOff = w.checkNextBlock(); !advanced {
+					break
+				}
+			}
+		case event := <-w.newBlocks:
+			for !turnOff && (event.Height == 0 || w.lastBlock < event.Height) {
+				var advanced bool
+
+				if advanced, turnOff = w.checkNextBlock(); !advanced {
+					break
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+// turnOff sends transaction to turn off masternode and retries until candidate is verified to be offline.
+// Failed broadcast or confirmation is retried too while candidate is still online.
+func (w *watcher) turnOff(ctx context.Context) error {
+	retries := w.cmd.config.Minter.Verification.Retries
+
+	if retries <= 0 {
+		retries = defaultVerificationRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		w.notify(notifier.Critical, "🚨 Sending transaction to turn off masternode")
+
+		err := w.turnOffMasternode(ctx)
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if w.cmd.dryRun && err == nil {
+			w.notify(notifier.Critical, "🚨 Masternode is off")
+			return nil
+		}
+
+		if err != nil {
+			w.newLogEntry(w.lastBlock).WithError(err).Errorln("Failed to turn off masternode")
+
+			w.notify(notifier.Critical, fmt.Sprintf("🚨 Failed to turn off masternode: %s", err))
+
+			if w.isCandidateOffline() {
+				w.notify(notifier.Critical, "🚨 Masternode is off")
+				return nil
+			}
+		} else {
+			included := time.Now()
+
+			if err = w.verifyOffline(ctx, included); err == nil {
+				w.notify(notifier.Critical, fmt.Sprintf("🚨 Masternode is off. Candidate went offline in %s", time.Since(included).Round(time.Second)))
+				return nil
+			}
+
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			w.newLogEntry(w.lastBlock).WithError(err).Errorln("Candidate is still online")
+		}
+
+		if attempt >= retries {
+			w.notify(notifier.Critical, fmt.Sprintf("🚨 %s. Giving up after %d retries, turn off masternode manually!", err, retries))
+			return err
+		}
+
+		w.notify(notifier.Critical, fmt.Sprintf("🚨 %s. Retrying [%d/%d]", err, attempt+1, retries))
+	}
+}
+
+func (w *watcher) setState(state string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.state = state
+}
+
+func (w *watcher) isPaused() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.paused
+}
+
+func (w *watcher) setPaused(paused bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.paused = paused
+}
+
+// requestTurnOff asks the running watcher to turn off masternode on its next iteration.
+func (w *watcher) requestTurnOff() error {
+	w.mu.RLock()
+	state := w.state
+	w.mu.RUnlock()
+
+	if state != control.StateWatching && state != control.StateWaiting {
+		return fmt.Errorf("watcher is %s", state)
+	}
+
+	select {
+	case w.turnOffRequests <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// requestReset asks the running watcher to clear the missed blocks window.
+func (w *watcher) requestReset() error {
+	w.mu.RLock()
+	state := w.state
+	w.mu.RUnlock()
+
+	if state != control.StateWatching && state != control.StateWaiting {
+		return fmt.Errorf("watcher is %s", state)
+	}
+
+	select {
+	case w.resetRequests <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (w *watcher) resetMissedBlocks() {
+	w.newLogEntry(w.lastBlock).Warnln("Missed blocks window reset")
+
+	w.mu.Lock()
+	w.missedBlocks = nil
+	w.mu.Unlock()
+
+	w.policy.Reset()
+	w.escalation = -1
+
+	if w.cmd.prometheus != nil {
+		w.cmd.prometheus.SetBlocksMissedCurrent(w.validator.PublicKey, 0)
+	}
+
+	w.saveState()
+
+	w.notify(notifier.Info, "♻️ Missed blocks window reset")
+}
+
+func (w *watcher) snapshot() control.Validator {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return control.Validator{
+		PublicKey:              w.validator.PublicKey,
+		LastBlock:              w.lastBlock,
+		MissedBlocks:           append([]int(nil), w.missedBlocks...),
+		MissedBlocksThreshold:  w.validator.MissedBlocksThreshold,
+		MissedBlockRemoveAfter: w.validator.MissedBlockRemoveAfter,
+		ControlAddress:         w.controlAddress,
+		Paused:                 w.paused,
+		DryRun:                 w.cmd.dryRun,
+		State:                  w.state,
+	}
+}
+
+// notifyNewBlock replaces pending event with the newer one, so the watcher always catches up to the latest height.
+func (w *watcher) notifyNewBlock(event node.NewBlockEvent) {
+	select {
+	case <-w.newBlocks:
+	default:
+	}
+
+	select {
+	case w.newBlocks <- event:
+	default:
+	}
+}
+
+func (w *watcher) checkNextBlock() (advanced bool, turnOff bool) {
+	if time.Now().Before(w.errors.retryAt) {
+		return false, false
+	}
+
+	w.cleanupMissedBlocks()
+
+	nextHeight := w.lastBlock + 1
+
+	outcome, err := w.checkBlock(nextHeight)
+
+	if err != nil {
+		if _, ok := err.(*node.BlockNotFound); ok {
+			w.recoverFromErrors(nextHeight)
+			w.newLogEntry(nextHeight).Debugln("Block not created yet.")
+			return false, false
+		}
+
+		if errors.Is(err, NoValidatorsSignedYet) {
+			w.recoverFromErrors(nextHeight)
+			return false, false
+		}
+
+		return false, w.handleCheckError(nextHeight, err)
+	}
+
+	w.recoverFromErrors(nextHeight)
+
+	w.mu.Lock()
+	w.lastBlock = nextHeight
+	w.mu.Unlock()
+
+	defer w.saveState()
+
+	publicKey := w.validator.PublicKey
+
+	if outcome == blockNotInSet {
+		w.leaveValidatorSet(nextHeight)
+
+		return true, false
+	}
+
+	w.returnToValidatorSet(nextHeight)
+
+	if outcome == blockExcluded {
+		w.excludeBlock(nextHeight)
+
+		return true, false
+	}
+
+	w.endNetworkMissed(nextHeight)
+
+	w.verdict = w.policy.Observe(nextHeight, outcome == blockSigned)
+
+	if outcome == blockSigned {
+		w.updateEscalation(nextHeight)
+
+		go func() {
+			if w.cmd.prometheus != nil {
+				w.cmd.prometheus.BlocksSignedIncrement(publicKey)
+			}
+		}()
+
+		w.newLogEntry(nextHeight).Println("Block signed")
+
+		return true, false
+	}
+
+	go func() {
+		if w.cmd.prometheus != nil {
+			go w.cmd.prometheus.BlocksMissedIncrement(publicKey)
+		}
+	}()
+
+	w.mu.Lock()
+	w.missedBlocks = append(w.missedBlocks, nextHeight)
+	w.mu.Unlock()
+
+	return true, w.escalate(nextHeight)
+}
+
+func (w *watcher) turnOffMasternode(ctx context.Context) error {
+	if w.cmd.dryRun {
+		w.newLogEntry(w.lastBlock).Warn("⚠️ Dry run. Masternode is still on!")
+		return nil
+	}
+
+	w.notify(notifier.Critical, "🚨 Setting masternode off...")
+
+	tx, resp, err := w.broadcastTurnOff(ctx)
+
+	if err == CandidateAlreadyOff {
+		w.newLogEntry(w.lastBlock).Warnln("Candidate is already off")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return w.confirmTransaction(ctx, tx, resp)
+}
+
+// checkCommission alerts when control address can't pay estimated commission of turn off transaction.
+func (w *watcher) checkCommission(c *node.CandidateOff) error {
+	if c.Commission == nil {
+		return nil
+	}
+
+	entry := w.backgroundLogEntry().
+		WithField("control_address", c.WalletAddress).
+		WithField("gas_coin", c.GasCoin).
+		WithField("gas_price", c.GasPrice).
+		WithField("commission", c.Commission).
+		WithField("balance", c.Balance)
+
+	low := c.Balance.Cmp(c.Commission) < 0
+
+	if low {
+		entry.Errorln("Balance of control address is not enough to pay commission of turn off transaction")
+	} else {
+		entry.Debugln("Commission of turn off transaction estimated")
+	}
+
+	w.mu.Lock()
+	changed := w.lowBalance != low
+	w.lowBalance = low
+	w.mu.Unlock()
+
+	if changed && low {
+		w.notify(notifier.Critical, fmt.Sprintf("🚨 Balance of %s in coin %d is %s pip, turn off transaction commission is %s pip", c.WalletAddress, c.GasCoin, c.Balance, c.Commission))
+	}
+
+	if low {
+		return InsufficientBalance
+	}
+
+	return nil
+}
+
+// checkGasPrice alerts when configured gas price cap is below network min gas price, so turn off transaction can't be built.
+func (w *watcher) checkGasPrice(err error) {
+	_, low := err.(*node.GasPriceBelowMin)
+
+	if err != nil && !low {
+		return
+	}
+
+	w.mu.Lock()
+	changed := w.lowGasPrice != low
+	w.lowGasPrice = low
+	w.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if low {
+		w.backgroundLogEntry().WithError(err).Errorln("Turn off transaction can't be signed")
+		w.notify(notifier.Critical, fmt.Sprintf("🚨 Turn off transaction can't be signed: %s. Raise gas.max_price", err))
+	} else {
+		w.notify(notifier.Info, "✅ Gas price is above min gas price again")
+	}
+}
+
+// canSign reports whether watcher can sign turn off transaction itself. Keys that don't control the candidate
+// disable signing, so only transaction_off is broadcast.
+func (w *watcher) canSign() bool {
+	if w.keysRejected {
+		return false
+	}
+
+	return len(w.keys) > 0 || w.cmd.signer != nil
+}
+
+func (w *watcher) generateTransactionOff() (string, error) {
+	c, err := w.cmd.minter.NewCandidateOff(w.validator.PublicKey, w.controlAddress)
+
+	if err != nil {
+		return "", err
+	}
+
+	// try anyway, balance could be topped up after the estimation
+	_ = w.checkCommission(c)
+
+	return w.signCandidateOff(c)
+}
+
+func (w *watcher) signCandidateOff(c *node.CandidateOff) (string, error) {
+	if len(w.keys) > 0 {
+		var privateKeys []string
+
+		for _, k := range w.keys {
+			privateKeys = append(privateKeys, k.PrivateKey)
+		}
+
+		return w.cmd.minter.SignCandidateOff(c, privateKeys...)
+	}
+
+	tx, err := w.cmd.minter.UnsignedCandidateOff(c)
+
+	if err != nil {
+		return "", err
+	}
+
+	return w.cmd.signer.Sign(tx, c.WalletAddress)
+}
+
+func (w *watcher) isSigned(height int) (bool, error) {
+	outcome, err := w.checkBlock(height)
+
+	return outcome == blockSigned, err
+}
+
+// checkBlock tells whether the block is signed by validator, missed while validator is in the set,
+// or validator is not in the validator set of the block at all.
+func (w *watcher) checkBlock(height int) (blockOutcome, error) {
+	block, err := w.cmd.minter.GetBlock(height)
+
+	if err != nil {
+		return blockMissed, err
+	}
+
+	if len(block.Validators) == 0 {
+		return blockMissed, NoValidatorsSignedYet
+	}
+
+	fraction := block.MissedFraction()
+	w.networkMissed = fraction
+
+	if w.cmd.prometheus != nil {
+		w.cmd.prometheus.SetNetworkMissedFraction(fraction)
+	}
+
+	signed, found := w.findValidator(block)
+
+	if !found {
+		return blockNotInSet, nil
+	}
+
+	if signed {
+		return blockSigned, nil
+	}
+
+	if w.isNetworkMissed(fraction) {
+		return blockExcluded, nil
+	}
+
+	if w.cmd.config.Minter.Quorum > 1 {
+		return w.confirmSigned(height)
+	}
+
+	return blockMissed, nil
+}
+
+// confirmSigned asks every node API about the block and reports it as missed only when quorum of them agree.
+// It's called only for blocks the primary node API reports missed, so disagreements are counted in that direction only:
+// asking every node API about each signed block would multiply the load on them.
+func (w *watcher) confirmSigned(height int) (blockOutcome, error) {
+	var signed, missed []string
+
+	quorum := w.cmd.config.Minter.Quorum
+
+	for _, result := range w.cmd.minter.GetBlockFromEach(height) {
+		if result.Err != nil || len(result.Block.Validators) == 0 {
+			continue
+		}
+
+		isSigned, found := w.findValidator(result.Block)
+
+		if !found {
+			continue
+		}
+
+		if isSigned {
+			signed = append(signed, result.Endpoint)
+		} else {
+			missed = append(missed, result.Endpoint)
+		}
+	}
+
+	if len(signed) > 0 && len(missed) > 0 {
+		w.newLogEntry(height).
+			WithField("signed_by", signed).
+			WithField("missed_by", missed).
+			Warnln("Node APIs disagree whether block is signed")
+
+		if w.cmd.prometheus != nil {
+			w.cmd.prometheus.NodeDisagreementsIncrement(w.validator.PublicKey)
+		}
+	}
+
+	if len(missed) >= quorum {
+		return blockMissed, nil
+	}
+
+	if len(signed) > 0 {
+		return blockSigned, nil
+	}
+
+	return blockMissed, fmt.Errorf("%w: %d of %d node APIs confirmed block is missed", QuorumNotReached, len(missed), quorum)
+}
+
+// findValidator reports whether validator is in the validator set of the block and whether it signed the block.
+func (w *watcher) findValidator(block *node.GetBlockResponse) (signed bool, found bool) {
+	for _, validator := range block.Validators {
+		if validator.PublicKey == w.validator.PublicKey {
+			return validator.Signed, true
+		}
+	}
+
+	return false, false
+}
+
+func (w *watcher) cleanupMissedBlocks() {
+	if len(w.missedBlocks) == 0 {
+		return
+	}
+
+	var temp []int
+
+	for _, height := range w.missedBlocks {
+		if w.lastBlock-height < w.validator.MissedBlockRemoveAfter {
+			temp = append(temp, height)
+		}
+	}
+
+	w.mu.Lock()
+	w.missedBlocks = temp
+	w.mu.Unlock()
+
+	if w.cmd.prometheus != nil {
+		w.cmd.prometheus.SetBlocksMissedCurrent(w.validator.PublicKey, len(w.missedBlocks))
+	}
+}
+
+func (w *watcher) notify(severity notifier.Severity, message string) {
+	if len(w.cmd.config.Minter.Validators) > 1 {
+		message = fmt.Sprintf("[%s] %s", shortPublicKey(w.validator.PublicKey), message)
+	}
+
+	w.cmd.notify(w.notifier, notifier.Message{
+		Severity:  severity,
+		Text:      message,
+		PublicKey: w.validator.PublicKey,
+	})
+}
+
+func (w *watcher) newLogEntry(height int) *logrus.Entry {
+	return w.logEntryAt(height, len(w.missedBlocks))
+}
+
+// backgroundLogEntry is newLogEntry for goroutines other than the watcher loop: it reads height and missed blocks under lock.
+func (w *watcher) backgroundLogEntry() *logrus.Entry {
+	w.mu.RLock()
+	height, missed := w.lastBlock, len(w.missedBlocks)
+	w.mu.RUnlock()
+
+	return w.logEntryAt(height, missed)
+}
+
+func (w *watcher) logEntryAt(height int, missed int) *logrus.Entry {
+	entry := w.cmd.log.
+		WithField("public_key", shortPublicKey(w.validator.PublicKey)).
+		WithField("height", height).
+		WithField("missed", missed)
+
+	if w.cmd.minter != nil {
+		entry = entry.WithField("node", w.cmd.minter.Endpoint())
+	}
+
+	return entry
+}
+
+func shortPublicKey(publicKey string) string {
+	if len(publicKey) <= 14 {
+		return publicKey
+	}
+
+	return publicKey[:8] + "…" + publicKey[len(publicKey)-6:]
+}
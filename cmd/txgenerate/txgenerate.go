@@ -38,6 +38,12 @@ func (cmd *Command) Command() *cli.Command {
 			if svc, err := node.New(cmd.config.Minter.NodeApi, cmd.config.Minter.Testnet, cmd.log); err != nil {
 				return err
 			} else {
+				svc.SetGas(
+					cmd.config.Minter.Gas.Coin,
+					cmd.config.Minter.Gas.PriceMultiplier,
+					cmd.config.Minter.Gas.MaxPrice,
+				)
+
 				cmd.minter = svc
 			}
 
@@ -7,23 +7,213 @@ import (
 
 type Config struct {
 	Telegram   Telegram   `yaml:"telegram"`
+	Slack      Slack      `yaml:"slack"`
+	Discord    Discord    `yaml:"discord"`
+	Webhook    Webhook    `yaml:"webhook"`
 	Minter     Minter     `yaml:"minter"`
 	Prometheus Prometheus `yaml:"prometheus"`
+	Api        Api        `yaml:"api"`
+	State      State      `yaml:"state"`
+	Keystore   Keystore   `yaml:"keystore"`
+	Signer     Signer     `yaml:"signer"`
 }
 type Telegram struct {
-	Token  string `yaml:"token"`
-	Admins []int  `yaml:"admins"`
+	Token      string   `yaml:"token"`
+	Admins     []int    `yaml:"admins"`
+	Severities []string `yaml:"severities"`
+}
+
+type Slack struct {
+	WebhookURL string   `yaml:"webhook_url"`
+	Severities []string `yaml:"severities"`
+}
+
+type Discord struct {
+	WebhookURL string   `yaml:"webhook_url"`
+	Severities []string `yaml:"severities"`
+}
+
+type Webhook struct {
+	URL        string   `yaml:"url"`
+	Secret     string   `yaml:"secret"`
+	Severities []string `yaml:"severities"`
 }
 
 type Minter struct {
-	Testnet                bool     `yaml:"testnet"`
-	NodeApi                []string `yaml:"node_api"`
-	PublicKey              string   `yaml:"public_key"`
-	TransactionOff         string   `yaml:"transaction_off"`
-	Seeds                  []string `yaml:"seeds"`
-	MissedBlocksThreshold  int      `yaml:"missed_blocks_threshold"`
-	Sleep                  int      `yaml:"sleep"`
-	MissedBlockRemoveAfter int      `yaml:"missed_block_remove_after"`
+	Testnet                  bool          `yaml:"testnet"`
+	NodeApi                  []string      `yaml:"node_api"`
+	Failover                 Failover      `yaml:"failover"`
+	Quorum                   int           `yaml:"quorum"`
+	PublicKey                string        `yaml:"public_key"`
+	TransactionOff           string        `yaml:"transaction_off"`
+	TransactionOffValidation string        `yaml:"transaction_off_validation"`
+	Seeds                    []string      `yaml:"seeds"`
+	Keystore                 string        `yaml:"keystore"`
+	RemoteSigner             RemoteSigner  `yaml:"remote_signer"`
+	Confirmation             Confirmation  `yaml:"confirmation"`
+	Verification             Verification  `yaml:"verification"`
+	Presign                  Presign       `yaml:"presign"`
+	Broadcast                Broadcast     `yaml:"broadcast"`
+	Gas                      Gas           `yaml:"gas"`
+	Balance                  Balance       `yaml:"balance"`
+	Rearm                    Rearm         `yaml:"rearm"`
+	MissedBlocksThreshold    int           `yaml:"missed_blocks_threshold"`
+	Sleep                    int           `yaml:"sleep"`
+	Subscribe                bool          `yaml:"subscribe"`
+	MissedBlockRemoveAfter   int           `yaml:"missed_block_remove_after"`
+	ErrorPolicy              ErrorPolicy   `yaml:"error_policy"`
+	Escalation               []Escalation  `yaml:"escalation"`
+	Policy                   Policy        `yaml:"policy"`
+	NetworkMissed            NetworkMissed `yaml:"network_missed"`
+	Validators               []Validator   `yaml:"validators"`
+}
+
+type Validator struct {
+	PublicKey              string       `yaml:"public_key"`
+	TransactionOff         string       `yaml:"transaction_off"`
+	Seeds                  []string     `yaml:"seeds"`
+	Keystore               string       `yaml:"keystore"`
+	MissedBlocksThreshold  int          `yaml:"missed_blocks_threshold"`
+	MissedBlockRemoveAfter int          `yaml:"missed_block_remove_after"`
+	TelegramAdmins         []int        `yaml:"telegram_admins"`
+	Notifiers              []string     `yaml:"notifiers"`
+	Escalation             []Escalation `yaml:"escalation"`
+	Policy                 Policy       `yaml:"policy"`
+}
+
+// ValidatorList returns validators to watch. Top-level public_key is used when validators list is empty,
+// and top-level thresholds are used for validators without their own.
+func (m Minter) ValidatorList() []Validator {
+	if len(m.Validators) == 0 {
+		return []Validator{
+			{
+				PublicKey:              m.PublicKey,
+				TransactionOff:         m.TransactionOff,
+				Seeds:                  m.Seeds,
+				Keystore:               m.Keystore,
+				MissedBlocksThreshold:  m.MissedBlocksThreshold,
+				MissedBlockRemoveAfter: m.MissedBlockRemoveAfter,
+				Escalation:             m.Escalation,
+				Policy:                 m.Policy,
+			},
+		}
+	}
+
+	validators := make([]Validator, 0, len(m.Validators))
+
+	for _, v := range m.Validators {
+		if v.MissedBlocksThreshold == 0 {
+			v.MissedBlocksThreshold = m.MissedBlocksThreshold
+		}
+
+		if v.MissedBlockRemoveAfter == 0 {
+			v.MissedBlockRemoveAfter = m.MissedBlockRemoveAfter
+		}
+
+		if len(v.Escalation) == 0 {
+			v.Escalation = m.Escalation
+		}
+
+		// Empty type means sliding policy, so the global policy is inherited only when validator sets none of the fields.
+		if v.Policy == (Policy{}) {
+			v.Policy = m.Policy
+		}
+
+		validators = append(validators, v)
+	}
+
+	return validators
+}
+
+type Failover struct {
+	FailureThreshold    int `yaml:"failure_threshold"`
+	Cooldown            int `yaml:"cooldown"`
+	HealthCheckInterval int `yaml:"health_check_interval"`
+	Timeout             int `yaml:"timeout"`
+}
+
+type Confirmation struct {
+	RebroadcastAfter int `yaml:"rebroadcast_after"`
+	MaxRebroadcasts  int `yaml:"max_rebroadcasts"`
+	Timeout          int `yaml:"timeout"`
+}
+
+type Verification struct {
+	Deadline int `yaml:"deadline"`
+	Retries  int `yaml:"retries"`
+}
+
+type Presign struct {
+	Interval int `yaml:"interval"`
+}
+
+type Gas struct {
+	Coin            uint64  `yaml:"coin"`
+	PriceMultiplier float64 `yaml:"price_multiplier"`
+	MaxPrice        int     `yaml:"max_price"`
+}
+
+type Balance struct {
+	Interval           int `yaml:"interval"`
+	CommissionMultiple int `yaml:"commission_multiple"`
+}
+
+type Rearm struct {
+	Interval int `yaml:"interval"`
+}
+
+type Retry struct {
+	Attempts int `yaml:"attempts"`
+	Delay    int `yaml:"delay"`
+}
+
+type Broadcast struct {
+	Signed          Retry `yaml:"signed"`
+	Resigned        Retry `yaml:"resigned"`
+	TransactionOff  Retry `yaml:"transaction_off"`
+	EachNode        Retry `yaml:"each_node"`
+	AlreadyOffCodes []int `yaml:"already_off_codes"`
+}
+
+// NetworkMissed excludes block from missed blocks window when more than ExcludeAbove percent of validators missed it.
+type NetworkMissed struct {
+	ExcludeAbove float64 `yaml:"exclude_above"`
+}
+
+// Policy decides when missed blocks are enough to turn off masternode.
+// Window and Threshold default to missed_block_remove_after and missed_blocks_threshold.
+type Policy struct {
+	Type      string  `yaml:"type"`
+	Window    int     `yaml:"window"`
+	Threshold int     `yaml:"threshold"`
+	Percent   float64 `yaml:"percent"`
+	Margin    int     `yaml:"margin"`
+}
+
+// Escalation is a rung of escalation ladder. It's reached when Missed blocks or Rate percent of the last Window blocks are missed,
+// or when missed blocks policy is exceeded if Policy is set.
+type Escalation struct {
+	Name    string             `yaml:"name"`
+	Missed  int                `yaml:"missed"`
+	Rate    float64            `yaml:"rate"`
+	Window  int                `yaml:"window"`
+	Policy  bool               `yaml:"policy"`
+	Actions []EscalationAction `yaml:"actions"`
+}
+
+type EscalationAction struct {
+	Type     string   `yaml:"type"`
+	Severity string   `yaml:"severity"`
+	URL      string   `yaml:"url"`
+	Secret   string   `yaml:"secret"`
+	Command  []string `yaml:"command"`
+}
+
+type ErrorPolicy struct {
+	Backoff      int `yaml:"backoff"`
+	MaxBackoff   int `yaml:"max_backoff"`
+	AlertAfter   int `yaml:"alert_after"`
+	TurnOffAfter int `yaml:"turn_off_after"`
 }
 
 type Prometheus struct {
@@ -31,6 +221,41 @@ type Prometheus struct {
 	Address string `yaml:"address"`
 }
 
+type Api struct {
+	Enabled bool   `yaml:"enabled"`
+	Address string `yaml:"address"`
+	Token   string `yaml:"token"`
+}
+
+type State struct {
+	Path string `yaml:"path"`
+}
+
+type TLS struct {
+	Cert string `yaml:"cert"`
+	Key  string `yaml:"key"`
+	CA   string `yaml:"ca"`
+}
+
+type RemoteSigner struct {
+	URL string `yaml:"url"`
+	TLS TLS    `yaml:"tls"`
+}
+
+type Signer struct {
+	Listen      string   `yaml:"listen"`
+	Keystore    string   `yaml:"keystore"`
+	PublicKeys  []string `yaml:"public_keys"`
+	GasCoins    []uint64 `yaml:"gas_coins"`
+	MaxGasPrice int      `yaml:"max_gas_price"`
+	TLS         TLS      `yaml:"tls"`
+}
+
+type Keystore struct {
+	PassphraseFile string `yaml:"passphrase_file"`
+	PassphraseEnv  string `yaml:"passphrase_env"`
+}
+
 func New(path string) (*Config, error) {
 	var cfg Config
 
@@ -0,0 +1,30 @@
+package config
+
+import "testing"
+
+func TestMinter_ValidatorList_Policy(t *testing.T) {
+	global := Policy{Type: "minter_jail", Margin: 2}
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		expected Policy
+	}{
+		{"inherited", Policy{}, global},
+		{"own type", Policy{Type: "consecutive", Threshold: 3}, Policy{Type: "consecutive", Threshold: 3}},
+		{"sliding without type", Policy{Window: 10, Threshold: 4}, Policy{Window: 10, Threshold: 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Minter{
+				Policy:     global,
+				Validators: []Validator{{PublicKey: "Mp01", Policy: tt.policy}},
+			}
+
+			if policy := m.ValidatorList()[0].Policy; policy != tt.expected {
+				t.Fatalf("wrong policy: expected %+v, got %+v", tt.expected, policy)
+			}
+		})
+	}
+}
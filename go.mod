@@ -6,8 +6,10 @@ require (
 	github.com/MinterTeam/minter-go-sdk/v2 v2.1.1
 	github.com/cristalhq/aconfig v0.13.1
 	github.com/cristalhq/aconfig/aconfigyaml v0.12.0
+	github.com/ethereum/go-ethereum v1.9.22
 	github.com/go-resty/resty/v2 v2.5.0
 	github.com/go-telegram-bot-api/telegram-bot-api v4.6.4+incompatible
+	github.com/gorilla/websocket v1.4.2
 	github.com/prometheus/client_golang v0.9.1
 	github.com/sirupsen/logrus v1.8.1
 	github.com/technoweenie/multipartstreamer v1.0.1 // indirect
@@ -2,6 +2,7 @@ package main
 
 import (
 	"minter-sentinel/cmd/seeds"
+	"minter-sentinel/cmd/signer"
 	"minter-sentinel/cmd/start"
 	"minter-sentinel/cmd/txgenerate"
 	"minter-sentinel/config"
@@ -21,6 +22,7 @@ func main() {
 	var cfg config.Config
 
 	seedsCmd := seeds.New(log, &cfg)
+	signerCmd := signer.New(log, &cfg)
 	startCmd := start.New(log, &cfg)
 	txGenerateCmd := txgenerate.New(log, &cfg)
 
@@ -56,6 +58,7 @@ func main() {
 		},
 		Commands: []*cli.Command{
 			seedsCmd.Command(),
+			signerCmd.Command(),
 			startCmd.Command(),
 			txGenerateCmd.Command(),
 		},
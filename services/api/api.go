@@ -0,0 +1,216 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"minter-sentinel/services/control"
+
+	"github.com/sirupsen/logrus"
+)
+
+const validatorsPath = "/api/validators"
+
+type Router interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+type Service struct {
+	address    string
+	token      string
+	controller control.Controller
+	logger     *logrus.Logger
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type healthResponse struct {
+	Status string         `json:"status"`
+	Nodes  []control.Node `json:"nodes"`
+}
+
+func New(address, token string, controller control.Controller, logger *logrus.Logger) (*Service, error) {
+	if len(token) == 0 {
+		return nil, errors.New("`api.token` is not set in configuration file")
+	}
+
+	return &Service{
+		address:    address,
+		token:      token,
+		controller: controller,
+		logger:     logger,
+	}, nil
+}
+
+// Register adds API handlers to the router, so API can be served next to other handlers (e.g. Prometheus metrics).
+func (s *Service) Register(router Router) {
+	router.Handle(validatorsPath, s.authorize(http.HandlerFunc(s.validators)))
+	router.Handle(validatorsPath+"/", s.authorize(http.HandlerFunc(s.validator)))
+	router.Handle("/healthz", http.HandlerFunc(s.healthz))
+	router.Handle("/readyz", http.HandlerFunc(s.readyz))
+}
+
+func (s *Service) Start() error {
+	mux := http.NewServeMux()
+
+	s.Register(mux)
+
+	return http.ListenAndServe(s.address, mux)
+}
+
+func (s *Service) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			s.logger.WithField("remote_addr", r.RemoteAddr).
+				WithField("path", r.URL.Path).
+				Warnln("Unauthorized API request")
+
+			s.writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validators handles GET /api/validators
+func (s *Service) validators(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, s.controller.Validators())
+}
+
+// validator handles GET /api/validators/{public_key} and POST /api/validators/{public_key}/{action}
+func (s *Service) validator(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, validatorsPath+"/"), "/")
+
+	v, err := control.MatchOne(s.controller.Validators(), parts[0])
+
+	if len(parts[0]) == 0 || err == control.ValidatorNotFound {
+		s.writeError(w, http.StatusNotFound, control.ValidatorNotFound)
+		return
+	}
+
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if len(parts) == 1 {
+		if r.Method != http.MethodGet {
+			s.writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+
+		s.writeJSON(w, http.StatusOK, v)
+		return
+	}
+
+	if len(parts) > 2 {
+		s.writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	var action func(publicKey string) error
+
+	switch parts[1] {
+	case "pause":
+		action = s.controller.Pause
+	case "resume":
+		action = s.controller.Resume
+	case "off":
+		action = s.controller.TurnOff
+	case "reset":
+		action = s.controller.Reset
+	default:
+		s.writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		s.writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	s.logger.WithField("public_key", v.PublicKey).
+		WithField("action", parts[1]).
+		WithField("remote_addr", r.RemoteAddr).
+		Println("API request")
+
+	if err := action(v.PublicKey); err != nil {
+		s.writeError(w, http.StatusConflict, err)
+		return
+	}
+
+	for _, updated := range s.controller.Validators() {
+		if updated.PublicKey == v.PublicKey {
+			s.writeJSON(w, http.StatusAccepted, updated)
+			return
+		}
+	}
+
+	s.writeJSON(w, http.StatusAccepted, v)
+}
+
+// healthz reports whether at least one Node API is reachable.
+func (s *Service) healthz(w http.ResponseWriter, _ *http.Request) {
+	nodes := s.controller.Nodes()
+
+	s.writeHealth(w, nodesUp(nodes), nodes)
+}
+
+// readyz additionally requires all validators to be watched.
+func (s *Service) readyz(w http.ResponseWriter, _ *http.Request) {
+	nodes := s.controller.Nodes()
+	ready := nodesUp(nodes)
+
+	for _, v := range s.controller.Validators() {
+		if v.State != control.StateWatching {
+			ready = false
+		}
+	}
+
+	s.writeHealth(w, ready, nodes)
+}
+
+func (s *Service) writeHealth(w http.ResponseWriter, ok bool, nodes []control.Node) {
+	if !ok {
+		s.writeJSON(w, http.StatusServiceUnavailable, healthResponse{Status: "unavailable", Nodes: nodes})
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, healthResponse{Status: "ok", Nodes: nodes})
+}
+
+func (s *Service) writeError(w http.ResponseWriter, code int, err error) {
+	s.writeJSON(w, code, errorResponse{Error: err.Error()})
+}
+
+func (s *Service) writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		s.logger.WithError(err).Errorln("Failed to write API response")
+	}
+}
+
+func nodesUp(nodes []control.Node) bool {
+	for _, n := range nodes {
+		if n.Up {
+			return true
+		}
+	}
+
+	return false
+}
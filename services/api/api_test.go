@@ -0,0 +1,173 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"minter-sentinel/services/control"
+
+	"github.com/sirupsen/logrus"
+)
+
+type fakeController struct {
+	validators []control.Validator
+	nodes      []control.Node
+	calls      []string
+}
+
+func (c *fakeController) Validators() []control.Validator {
+	return c.validators
+}
+
+func (c *fakeController) Candidate(string) (*control.Candidate, error) {
+	return &control.Candidate{}, nil
+}
+
+func (c *fakeController) Pause(publicKey string) error {
+	c.calls = append(c.calls, "pause "+publicKey)
+	c.validators[0].Paused = true
+
+	return nil
+}
+
+func (c *fakeController) Resume(publicKey string) error {
+	c.calls = append(c.calls, "resume "+publicKey)
+
+	return nil
+}
+
+func (c *fakeController) TurnOff(string) error {
+	return errors.New("watcher is off")
+}
+
+func (c *fakeController) Reset(publicKey string) error {
+	c.calls = append(c.calls, "reset "+publicKey)
+
+	return nil
+}
+
+func (c *fakeController) Nodes() []control.Node {
+	return c.nodes
+}
+
+func newTestServer(controller control.Controller) *httptest.Server {
+	svc, _ := New("", "secret", controller, logrus.New())
+
+	mux := http.NewServeMux()
+	svc.Register(mux)
+
+	return httptest.NewServer(mux)
+}
+
+func request(t *testing.T, method, url, token string) *http.Response {
+	req, _ := http.NewRequest(method, url, nil)
+
+	if len(token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+
+	return resp
+}
+
+func TestService_Validators(t *testing.T) {
+	controller := &fakeController{validators: []control.Validator{{PublicKey: "Mp01aa", LastBlock: 10, State: control.StateWatching}}}
+
+	server := newTestServer(controller)
+	defer server.Close()
+
+	if resp := request(t, http.MethodGet, server.URL+"/api/validators", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("request without token should be rejected, got %d", resp.StatusCode)
+	}
+
+	if resp := request(t, http.MethodGet, server.URL+"/api/validators", "wrong"); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("request with wrong token should be rejected, got %d", resp.StatusCode)
+	}
+
+	resp := request(t, http.MethodGet, server.URL+"/api/validators/Mp01", "secret")
+	defer resp.Body.Close()
+
+	var v control.Validator
+
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("wrong response: %d, %v", resp.StatusCode, err)
+	}
+
+	if v.PublicKey != "Mp01aa" || v.LastBlock != 10 {
+		t.Fatalf("wrong validator: %+v", v)
+	}
+
+	if resp := request(t, http.MethodGet, server.URL+"/api/validators/Mp02", "secret"); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected not found, got %d", resp.StatusCode)
+	}
+}
+
+func TestService_Actions(t *testing.T) {
+	controller := &fakeController{validators: []control.Validator{{PublicKey: "Mp01aa", State: control.StateWatching}}}
+
+	server := newTestServer(controller)
+	defer server.Close()
+
+	if resp := request(t, http.MethodGet, server.URL+"/api/validators/Mp01/pause", "secret"); resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected method not allowed, got %d", resp.StatusCode)
+	}
+
+	resp := request(t, http.MethodPost, server.URL+"/api/validators/Mp01/pause", "secret")
+	defer resp.Body.Close()
+
+	var v control.Validator
+
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil || resp.StatusCode != http.StatusAccepted || !v.Paused {
+		t.Fatalf("wrong response: %d, %+v, %v", resp.StatusCode, v, err)
+	}
+
+	if resp := request(t, http.MethodPost, server.URL+"/api/validators/Mp01/reset", "secret"); resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("wrong status: %d", resp.StatusCode)
+	}
+
+	if resp := request(t, http.MethodPost, server.URL+"/api/validators/Mp01/off", "secret"); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected conflict, got %d", resp.StatusCode)
+	}
+
+	if len(controller.calls) != 2 || controller.calls[0] != "pause Mp01aa" || controller.calls[1] != "reset Mp01aa" {
+		t.Fatalf("wrong calls: %v", controller.calls)
+	}
+}
+
+func TestService_Health(t *testing.T) {
+	controller := &fakeController{
+		validators: []control.Validator{{PublicKey: "Mp01aa", State: control.StateStarting}},
+		nodes:      []control.Node{{URL: "http://node", Up: true}},
+	}
+
+	server := newTestServer(controller)
+	defer server.Close()
+
+	if resp := request(t, http.MethodGet, server.URL+"/healthz", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected healthy, got %d", resp.StatusCode)
+	}
+
+	if resp := request(t, http.MethodGet, server.URL+"/readyz", ""); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected not ready while starting, got %d", resp.StatusCode)
+	}
+
+	controller.validators[0].State = control.StateWatching
+
+	if resp := request(t, http.MethodGet, server.URL+"/readyz", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected ready, got %d", resp.StatusCode)
+	}
+
+	controller.nodes[0].Up = false
+
+	if resp := request(t, http.MethodGet, server.URL+"/healthz", ""); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected unhealthy, got %d", resp.StatusCode)
+	}
+}
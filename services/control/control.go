@@ -0,0 +1,90 @@
+package control
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ValidatorNotFound  = errors.New("validator not found")
+	ValidatorAmbiguous = errors.New("several validators match, specify public key")
+)
+
+const (
+	StateStarting   = "starting"
+	StateWatching   = "watching"
+	StateWaiting    = "waiting"
+	StateTurningOff = "turning_off"
+	StateOff        = "off"
+	StateFailed     = "failed"
+	StateStopped    = "stopped"
+)
+
+type Validator struct {
+	PublicKey              string `json:"public_key"`
+	LastBlock              int    `json:"last_block"`
+	MissedBlocks           []int  `json:"missed_blocks"`
+	MissedBlocksThreshold  int    `json:"missed_blocks_threshold"`
+	MissedBlockRemoveAfter int    `json:"missed_block_remove_after"`
+	ControlAddress         string `json:"control_address"`
+	Paused                 bool   `json:"paused"`
+	DryRun                 bool   `json:"dry_run"`
+	State                  string `json:"state"`
+}
+
+type Candidate struct {
+	Status      int  `json:"status"`
+	Validator   bool `json:"validator"`
+	JailedUntil int  `json:"jailed_until"`
+}
+
+type Node struct {
+	URL       string `json:"url"`
+	Up        bool   `json:"up"`
+	Height    int    `json:"height"`
+	Active    bool   `json:"active"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Controller is implemented by the watcher and used by interactive interfaces (Telegram bot, HTTP API) to operate it.
+type Controller interface {
+	Validators() []Validator
+	Candidate(publicKey string) (*Candidate, error)
+	Pause(publicKey string) error
+	Resume(publicKey string) error
+	TurnOff(publicKey string) error
+	Reset(publicKey string) error
+	Nodes() []Node
+}
+
+// Match returns validators which public key starts with the given prefix. All validators are returned for empty prefix.
+func Match(validators []Validator, prefix string) []Validator {
+	if len(prefix) == 0 {
+		return validators
+	}
+
+	var matched []Validator
+
+	for _, v := range validators {
+		if strings.HasPrefix(v.PublicKey, prefix) {
+			matched = append(matched, v)
+		}
+	}
+
+	return matched
+}
+
+// MatchOne returns the only validator matching the prefix.
+func MatchOne(validators []Validator, prefix string) (*Validator, error) {
+	matched := Match(validators, prefix)
+
+	if len(matched) == 0 {
+		return nil, ValidatorNotFound
+	}
+
+	if len(matched) > 1 {
+		return nil, ValidatorAmbiguous
+	}
+
+	return &matched[0], nil
+}
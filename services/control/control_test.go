@@ -0,0 +1,27 @@
+package control
+
+import "testing"
+
+func TestMatchOne(t *testing.T) {
+	validators := []Validator{
+		{PublicKey: "Mp01aa"},
+		{PublicKey: "Mp01bb"},
+		{PublicKey: "Mp02cc"},
+	}
+
+	if v, err := MatchOne(validators, "Mp02"); err != nil || v.PublicKey != "Mp02cc" {
+		t.Fatalf("wrong match: %+v, %v", v, err)
+	}
+
+	if _, err := MatchOne(validators, "Mp01"); err != ValidatorAmbiguous {
+		t.Fatalf("expected ambiguous error, got %v", err)
+	}
+
+	if _, err := MatchOne(validators, "Mp03"); err != ValidatorNotFound {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	if v, err := MatchOne(validators[:1], ""); err != nil || v.PublicKey != "Mp01aa" {
+		t.Fatalf("single validator should match empty prefix: %+v, %v", v, err)
+	}
+}
@@ -0,0 +1,55 @@
+package discord
+
+import (
+	"errors"
+	"fmt"
+	"minter-sentinel/services/notifier"
+
+	"github.com/go-resty/resty/v2"
+)
+
+type Service struct {
+	webhookURL string
+	http       *resty.Client
+}
+
+type message struct {
+	Content string `json:"content"`
+}
+
+func New(webhookURL string) (*Service, error) {
+	if len(webhookURL) == 0 {
+		return nil, errors.New("discord webhook url is not set")
+	}
+
+	return &Service{
+		webhookURL: webhookURL,
+		http:       resty.New().SetRetryCount(2),
+	}, nil
+}
+
+func (s *Service) Name() string {
+	return "discord"
+}
+
+func (s *Service) Notify(msg notifier.Message) error {
+	content := msg.Text
+
+	if msg.Urgent {
+		content = "@everyone " + content
+	}
+
+	resp, err := s.http.R().
+		SetBody(&message{Content: content}).
+		Post(s.webhookURL)
+
+	if err != nil {
+		return err
+	}
+
+	if resp.IsError() {
+		return fmt.Errorf("discord responded with status %d: %s", resp.StatusCode(), resp.String())
+	}
+
+	return nil
+}
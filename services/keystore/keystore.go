@@ -0,0 +1,277 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/MinterTeam/minter-go-sdk/v2/wallet"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	version    = 1
+	kdf        = "scrypt"
+	cipherName = "aes-256-gcm"
+	keyLen     = 32
+	saltLen    = 32
+)
+
+var (
+	WrongPassphrase = errors.New("wrong passphrase or corrupted keystore")
+	DefaultParams   = Params{N: 1 << 16, R: 8, P: 1}
+)
+
+type Params struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt string `json:"salt"`
+}
+
+type Crypto struct {
+	Cipher     string `json:"cipher"`
+	CipherText string `json:"ciphertext"`
+	Nonce      string `json:"nonce"`
+	KDF        string `json:"kdf"`
+	KDFParams  Params `json:"kdfparams"`
+}
+
+// File is stored on disk. Addresses are kept in plain text to see which wallets keystore holds without unlocking it.
+type File struct {
+	Version   int      `json:"version"`
+	Addresses []string `json:"addresses"`
+	Crypto    Crypto   `json:"crypto"`
+}
+
+type payload struct {
+	Seeds []string `json:"seeds"`
+}
+
+type Key struct {
+	Address    string
+	PrivateKey string
+}
+
+// Encrypt derives wallets from seeds and encrypts seeds with a key derived from passphrase.
+func Encrypt(seeds []string, passphrase []byte, params Params) (*File, error) {
+	if len(seeds) == 0 {
+		return nil, errors.New("no seeds to encrypt")
+	}
+
+	keys, err := FromSeeds(seeds)
+
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, saltLen)
+
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	params.Salt = hex.EncodeToString(salt)
+
+	aead, err := newAEAD(passphrase, params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	plaintext, err := json.Marshal(payload{Seeds: seeds})
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer wipe(plaintext)
+
+	f := &File{
+		Version: version,
+		Crypto: Crypto{
+			Cipher:    cipherName,
+			Nonce:     hex.EncodeToString(nonce),
+			KDF:       kdf,
+			KDFParams: params,
+		},
+	}
+
+	for _, k := range keys {
+		f.Addresses = append(f.Addresses, k.Address)
+	}
+
+	f.Crypto.CipherText = hex.EncodeToString(aead.Seal(nil, nonce, plaintext, f.additionalData()))
+
+	return f, nil
+}
+
+// Decrypt returns private keys of wallets stored in keystore. Seeds are not kept after keys are derived.
+func (f *File) Decrypt(passphrase []byte) ([]Key, error) {
+	if f.Version != version || f.Crypto.KDF != kdf || f.Crypto.Cipher != cipherName {
+		return nil, fmt.Errorf("unsupported keystore version %d (%s, %s)", f.Version, f.Crypto.KDF, f.Crypto.Cipher)
+	}
+
+	aead, err := newAEAD(passphrase, f.Crypto.KDFParams)
+
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := hex.DecodeString(f.Crypto.Nonce)
+
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return nil, errors.New("malformed keystore nonce")
+	}
+
+	ciphertext, err := hex.DecodeString(f.Crypto.CipherText)
+
+	if err != nil {
+		return nil, errors.New("malformed keystore ciphertext")
+	}
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, f.additionalData())
+
+	if err != nil {
+		return nil, WrongPassphrase
+	}
+
+	defer wipe(plaintext)
+
+	var p payload
+
+	if err := json.Unmarshal(plaintext, &p); err != nil {
+		return nil, err
+	}
+
+	keys, err := FromSeeds(p.Seeds)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) != len(f.Addresses) {
+		return nil, errors.New("keystore addresses don't match its seeds")
+	}
+
+	for i, k := range keys {
+		if k.Address != f.Addresses[i] {
+			return nil, errors.New("keystore addresses don't match its seeds")
+		}
+	}
+
+	return keys, nil
+}
+
+func Load(path string) (*File, error) {
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var f File
+
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("malformed keystore %s: %w", path, err)
+	}
+
+	return &f, nil
+}
+
+// Save writes keystore readable by owner only. Existing file is never overwritten.
+func (f *File) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// additionalData binds public parts of the file to ciphertext, so they can't be altered unnoticed.
+func (f *File) additionalData() []byte {
+	data, _ := json.Marshal(struct {
+		Version   int      `json:"version"`
+		Addresses []string `json:"addresses"`
+		KDFParams Params   `json:"kdfparams"`
+	}{f.Version, f.Addresses, f.Crypto.KDFParams})
+
+	return data
+}
+
+func newAEAD(passphrase []byte, params Params) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(params.Salt)
+
+	if err != nil || len(salt) == 0 {
+		return nil, errors.New("malformed keystore salt")
+	}
+
+	key, err := scrypt.Key(passphrase, salt, params.N, params.R, params.P, keyLen)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer wipe(key)
+
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// FromSeeds derives keys from plain text seeds.
+func FromSeeds(seeds []string) ([]Key, error) {
+	var keys []Key
+
+	for _, seed := range seeds {
+		wal, err := wallet.Create("", seed)
+
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, Key{Address: wal.Address, PrivateKey: wal.PrivateKey})
+	}
+
+	return keys, nil
+}
+
+func wipe(data []byte) {
+	for i := range data {
+		data[i] = 0
+	}
+}
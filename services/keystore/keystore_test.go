@@ -0,0 +1,84 @@
+package keystore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const seed = "4518edc842a0edbf1576c69afd04e66649655c166b8805ffca9926eb942c7fc4271f766eac16887a66e302f0daa70df7893bd3fb138eab9042f1ac02d866cf3a"
+const address = "Mx4e16a6bfc1bac5f4cf94ef60ab5047510a32abbc"
+
+var testParams = Params{N: 1 << 10, R: 8, P: 1}
+
+func TestFile_EncryptDecrypt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "minter-sentinel")
+
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "keystore.json")
+
+	f, err := Encrypt([]string{seed}, []byte("passphrase"), testParams)
+
+	if err != nil {
+		t.Fatalf("failed to encrypt: %s", err)
+	}
+
+	if err := f.Save(path); err != nil {
+		t.Fatalf("failed to save: %s", err)
+	}
+
+	if err := f.Save(path); err == nil {
+		t.Fatalf("existing keystore should not be overwritten")
+	}
+
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Fatalf("wrong permissions: %s", info.Mode())
+	}
+
+	data, _ := ioutil.ReadFile(path)
+
+	if len(data) == 0 || strings.Contains(string(data), seed) {
+		t.Fatalf("seed is stored in plain text")
+	}
+
+	loaded, err := Load(path)
+
+	if err != nil {
+		t.Fatalf("failed to load: %s", err)
+	}
+
+	if _, err := loaded.Decrypt([]byte("wrong")); err != WrongPassphrase {
+		t.Fatalf("expected wrong passphrase error, got %v", err)
+	}
+
+	keys, err := loaded.Decrypt([]byte("passphrase"))
+
+	if err != nil {
+		t.Fatalf("failed to decrypt: %s", err)
+	}
+
+	if len(keys) != 1 || keys[0].Address != address || len(keys[0].PrivateKey) == 0 {
+		t.Fatalf("wrong keys: %+v", keys)
+	}
+}
+
+func TestFile_Tampered(t *testing.T) {
+	f, err := Encrypt([]string{seed}, []byte("passphrase"), testParams)
+
+	if err != nil {
+		t.Fatalf("failed to encrypt: %s", err)
+	}
+
+	f.Addresses = []string{"Mx0000000000000000000000000000000000000000"}
+
+	if _, err := f.Decrypt([]byte("passphrase")); err != WrongPassphrase {
+		t.Fatalf("tampered keystore should not be decrypted, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package keystore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"syscall"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const DefaultPassphraseEnv = "SENTINEL_KEYSTORE_PASSPHRASE"
+
+// Passphrase reads passphrase from file, then from environment variable, and prompts for it if stdin is a terminal.
+func Passphrase(file string, env string) ([]byte, error) {
+	if len(file) > 0 {
+		data, err := ioutil.ReadFile(file)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase file: %w", err)
+		}
+
+		return bytes.TrimRight(data, "\r\n"), nil
+	}
+
+	if len(env) == 0 {
+		env = DefaultPassphraseEnv
+	}
+
+	if value, ok := os.LookupEnv(env); ok {
+		_ = os.Unsetenv(env)
+
+		return []byte(value), nil
+	}
+
+	if !terminal.IsTerminal(int(syscall.Stdin)) {
+		return nil, fmt.Errorf("keystore passphrase is not set: use passphrase file or %s environment variable", env)
+	}
+
+	return Prompt("Keystore passphrase (hidden): ")
+}
+
+func Prompt(prompt string) ([]byte, error) {
+	fmt.Print(prompt)
+
+	passphrase, err := terminal.ReadPassword(int(syscall.Stdin))
+
+	fmt.Println()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(passphrase) == 0 {
+		return nil, errors.New("empty passphrase")
+	}
+
+	return passphrase, nil
+}
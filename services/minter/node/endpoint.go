@@ -0,0 +1,180 @@
+package node
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	defaultFailureThreshold = 3
+	defaultCooldown         = 30 * time.Second
+	defaultTimeout          = 5 * time.Second
+
+	latencyWeight = 0.3
+	errorWeight   = 0.2
+
+	// Every block the endpoint lags behind the freshest one costs as much as a second of latency.
+	lagPenalty = float64(time.Second)
+)
+
+type EndpointHealth struct {
+	URL        string
+	Latency    time.Duration
+	ErrorRate  float64
+	Height     int
+	Open       bool
+	OpenUntil  time.Time
+	LastError  string
+	LastUsedAt time.Time
+}
+
+type endpoint struct {
+	url  string
+	http *resty.Client
+
+	mu                  sync.Mutex
+	latency             float64
+	errorRate           float64
+	height              int
+	consecutiveFailures int
+	openUntil           time.Time
+	lastError           string
+	lastUsedAt          time.Time
+}
+
+// newEndpoint creates endpoint with request timeout, so a node that accepts connection but never replies
+// counts as a failure and the next endpoint is tried.
+func newEndpoint(url string, timeout time.Duration) *endpoint {
+	return &endpoint{
+		url: url,
+		http: resty.New().
+			SetRetryCount(1).
+			SetTimeout(timeout).
+			SetHostURL(url),
+	}
+}
+
+func (e *endpoint) open(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.openUntil.After(now)
+}
+
+func (e *endpoint) score(maxHeight int) float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	score := e.latency * (1 + 10*e.errorRate)
+
+	if e.height > 0 && maxHeight > e.height {
+		score += float64(maxHeight-e.height) * lagPenalty
+	}
+
+	return score
+}
+
+func (e *endpoint) observe(duration time.Duration, err error, failureThreshold int, cooldown time.Duration) (opened bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+
+	e.lastUsedAt = now
+
+	if e.latency == 0 {
+		e.latency = float64(duration)
+	} else {
+		e.latency = (1-latencyWeight)*e.latency + latencyWeight*float64(duration)
+	}
+
+	if err == nil {
+		e.errorRate = (1 - errorWeight) * e.errorRate
+		e.consecutiveFailures = 0
+		e.openUntil = time.Time{}
+		e.lastError = ""
+
+		return false
+	}
+
+	e.errorRate = (1-errorWeight)*e.errorRate + errorWeight
+	e.consecutiveFailures++
+	e.lastError = err.Error()
+
+	if e.consecutiveFailures >= failureThreshold && !e.openUntil.After(now) {
+		e.openUntil = now.Add(cooldown)
+
+		return true
+	}
+
+	return false
+}
+
+func (e *endpoint) setHeight(height int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if height > e.height {
+		e.height = height
+	}
+}
+
+func (e *endpoint) health() EndpointHealth {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return EndpointHealth{
+		URL:        e.url,
+		Latency:    time.Duration(e.latency),
+		ErrorRate:  e.errorRate,
+		Height:     e.height,
+		Open:       e.openUntil.After(time.Now()),
+		OpenUntil:  e.openUntil,
+		LastError:  e.lastError,
+		LastUsedAt: e.lastUsedAt,
+	}
+}
+
+func (svc *Service) maxHeight() int {
+	height := 0
+
+	for _, e := range svc.endpoints {
+		e.mu.Lock()
+		if e.height > height {
+			height = e.height
+		}
+		e.mu.Unlock()
+	}
+
+	return height
+}
+
+// ranked returns endpoints with closed circuits ordered from the healthiest one.
+// Endpoints with open circuits are appended at the end, so they are used only if all others fail.
+func (svc *Service) ranked() []*endpoint {
+	now := time.Now()
+	maxHeight := svc.maxHeight()
+
+	var closed, open []*endpoint
+
+	for _, e := range svc.endpoints {
+		if e.open(now) {
+			open = append(open, e)
+		} else {
+			closed = append(closed, e)
+		}
+	}
+
+	sort.SliceStable(closed, func(i, j int) bool {
+		return closed[i].score(maxHeight) < closed[j].score(maxHeight)
+	})
+
+	sort.SliceStable(open, func(i, j int) bool {
+		return open[i].health().OpenUntil.Before(open[j].health().OpenUntil)
+	})
+
+	return append(closed, open...)
+}
@@ -0,0 +1,240 @@
+package node
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newStatusServer(code int, height int, hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+
+		_, _ = w.Write([]byte(`{"latest_block_height":"` + strconv.Itoa(height) + `","catching_up":false}`))
+	}))
+}
+
+func TestService_CircuitBreaker(t *testing.T) {
+	var brokenHits, healthyHits int32
+
+	broken := newStatusServer(http.StatusBadGateway, 0, &brokenHits)
+	defer broken.Close()
+
+	healthy := newStatusServer(http.StatusOK, 100, &healthyHits)
+	defer healthy.Close()
+
+	svc, _ := New([]string{broken.URL, healthy.URL}, true, nil)
+	svc.SetCircuitBreaker(2, time.Minute)
+
+	svc.CheckHealth()
+	svc.CheckHealth()
+
+	for i := 0; i < 3; i++ {
+		status, err := svc.Status()
+
+		if err != nil {
+			t.Fatalf("failed to get status: %s", err)
+		}
+
+		if status.LatestBlockHeight != 100 {
+			t.Fatalf("wrong height: expected 100, got %d", status.LatestBlockHeight)
+		}
+	}
+
+	if svc.Endpoint() != healthy.URL {
+		t.Fatalf("wrong endpoint: expected %s, got %s", healthy.URL, svc.Endpoint())
+	}
+
+	health := svc.Health()
+
+	if !health[0].Open {
+		t.Fatalf("circuit of broken endpoint should be open")
+	}
+
+	if health[1].Open || health[1].Height != 100 {
+		t.Fatalf("wrong health of healthy endpoint: %+v", health[1])
+	}
+
+	hits := atomic.LoadInt32(&brokenHits)
+
+	if _, err := svc.Status(); err != nil {
+		t.Fatalf("failed to get status: %s", err)
+	}
+
+	if atomic.LoadInt32(&brokenHits) != hits {
+		t.Fatalf("ejected endpoint should not be requested")
+	}
+}
+
+func TestService_Timeout(t *testing.T) {
+	var healthyHits int32
+
+	done := make(chan struct{})
+
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer hanging.Close()
+	defer close(done)
+
+	healthy := newStatusServer(http.StatusOK, 100, &healthyHits)
+	defer healthy.Close()
+
+	svc, _ := New([]string{hanging.URL, healthy.URL}, true, nil)
+	svc.SetCircuitBreaker(1, time.Minute)
+	svc.SetTimeout(50 * time.Millisecond)
+
+	status, err := svc.Status()
+
+	if err != nil {
+		t.Fatalf("failed to get status: %s", err)
+	}
+
+	if status.LatestBlockHeight != 100 {
+		t.Fatalf("wrong height: expected 100, got %d", status.LatestBlockHeight)
+	}
+
+	if health := svc.Health(); !health[0].Open {
+		t.Fatalf("circuit of hanging endpoint should be open")
+	}
+}
+
+func TestService_RankedByFreshness(t *testing.T) {
+	var staleHits, freshHits int32
+
+	stale := newStatusServer(http.StatusOK, 90, &staleHits)
+	defer stale.Close()
+
+	fresh := newStatusServer(http.StatusOK, 100, &freshHits)
+	defer fresh.Close()
+
+	svc, _ := New([]string{stale.URL, fresh.URL}, true, nil)
+
+	svc.CheckHealth()
+
+	if svc.Endpoint() != fresh.URL {
+		t.Fatalf("wrong endpoint: expected %s, got %s", fresh.URL, svc.Endpoint())
+	}
+}
+
+func TestService_GetBlockFromEach(t *testing.T) {
+	block := func(signed bool) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+
+			_, _ = w.Write([]byte(`{"height":"10","validators":[{"public_key":"` + publicKey + `","signed":` + strconv.FormatBool(signed) + `}]}`))
+		}))
+	}
+
+	signed := block(true)
+	defer signed.Close()
+
+	missed := block(false)
+	defer missed.Close()
+
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+
+	svc, _ := New([]string{signed.URL, missed.URL, notFound.URL}, true, nil)
+
+	results := svc.GetBlockFromEach(10)
+
+	if len(results) != 3 {
+		t.Fatalf("wrong number of results: %d", len(results))
+	}
+
+	if results[0].Err != nil || !results[0].Block.Validators[0].Signed {
+		t.Fatalf("wrong result of %s: %+v", results[0].Endpoint, results[0])
+	}
+
+	if results[1].Err != nil || results[1].Block.Validators[0].Signed {
+		t.Fatalf("wrong result of %s: %+v", results[1].Endpoint, results[1])
+	}
+
+	if _, ok := results[2].Err.(*BlockNotFound); !ok {
+		t.Fatalf("expected block not found error, got %v", results[2].Err)
+	}
+}
+
+func TestService_GetTransaction(t *testing.T) {
+	var included int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if atomic.LoadInt32(&included) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":"404","message":"Tx not found"}}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"hash":"Mt01","height":"15","code":"0","log":""}`))
+	}))
+	defer server.Close()
+
+	svc, _ := New([]string{server.URL}, true, nil)
+
+	if _, err := svc.GetTransaction("Mt01"); err == nil {
+		t.Fatalf("expected transaction not found error")
+	} else if _, ok := err.(*TransactionNotFound); !ok {
+		t.Fatalf("expected transaction not found error, got %v", err)
+	}
+
+	atomic.StoreInt32(&included, 1)
+
+	tx, err := svc.GetTransaction("Mt01")
+
+	if err != nil {
+		t.Fatalf("failed to get transaction: %s", err)
+	}
+
+	if tx.Height != 15 || tx.Code != 0 || tx.Endpoint != server.URL {
+		t.Fatalf("wrong transaction: %+v", tx)
+	}
+}
+
+func TestService_BroadcastTransaction(t *testing.T) {
+	var firstHits, secondHits int32
+
+	send := func(hits *int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(hits, 1)
+
+			w.Header().Set("Content-Type", "application/json")
+
+			_, _ = w.Write([]byte(`{"code":"0","log":"","hash":"Mt01"}`))
+		}))
+	}
+
+	first := send(&firstHits)
+	defer first.Close()
+
+	second := send(&secondHits)
+	defer second.Close()
+
+	svc, _ := New([]string{first.URL, second.URL}, true, nil)
+
+	results := svc.BroadcastTransaction("0x01", first.URL)
+
+	if len(results) != 1 || results[0].Endpoint != second.URL || results[0].Err != nil {
+		t.Fatalf("wrong results: %+v", results)
+	}
+
+	if results[0].Response.Hash != "Mt01" {
+		t.Fatalf("hash is not parsed: %+v", results[0].Response)
+	}
+
+	if atomic.LoadInt32(&firstHits) != 0 || atomic.LoadInt32(&secondHits) != 1 {
+		t.Fatalf("excluded endpoint should not be used")
+	}
+
+	if results := svc.BroadcastTransaction("0x01", first.URL, second.URL); len(results) != 2 {
+		t.Fatalf("transaction should be sent through all endpoints if all are excluded, got %d results", len(results))
+	}
+}
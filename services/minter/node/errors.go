@@ -20,3 +20,20 @@ func NewBlockNotFoundError(resp *GetBlockResponse) *BlockNotFound {
 func (e *BlockNotFound) Error() string {
 	return fmt.Sprintf("%d: %s", e.resp.Error.Code, e.resp.Error.Message)
 }
+
+type GasPriceBelowMin struct {
+	price       uint8
+	minGasPrice uint8
+}
+
+func (e *GasPriceBelowMin) Error() string {
+	return fmt.Sprintf("gas price %d is capped below min gas price %d", e.price, e.minGasPrice)
+}
+
+type TransactionNotFound struct {
+	hash string
+}
+
+func (e *TransactionNotFound) Error() string {
+	return fmt.Sprintf("transaction %s not found", e.hash)
+}
@@ -1,12 +1,22 @@
 package node
 
-import "time"
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+)
 
 type StatusResponse struct {
 	LatestBlockHeight int  `json:"latest_block_height,string"`
 	CatchingUp        bool `json:"catching_up"`
 }
 
+const (
+	CandidateStatusOffline = 1
+	CandidateStatusOnline  = 2
+)
+
 type CandidateResponse struct {
 	ControlAddress string `json:"control_address"`
 	Status         int    `json:"status,string"`
@@ -32,7 +42,8 @@ type GetBlockResponse struct {
 	} `json:"evidence"`
 	Missed []interface{} `json:"missed"`
 
-	Error *Error `json:"error"`
+	Error    *Error `json:"error"`
+	Endpoint string `json:"-"`
 }
 
 type MissedBlocksResponse struct {
@@ -42,20 +53,137 @@ type MissedBlocksResponse struct {
 	Error *Error `json:"error"`
 }
 
+type Multisig struct {
+	Threshold int      `json:"threshold,string"`
+	Weights   []string `json:"weights"`
+	Addresses []string `json:"addresses"`
+}
+
+// Weight returns combined weight of the given owners.
+func (m *Multisig) Weight(addresses []string) (int, error) {
+	weight := 0
+
+	for i, owner := range m.Addresses {
+		for _, address := range addresses {
+			if address != owner {
+				continue
+			}
+
+			if i >= len(m.Weights) {
+				return 0, fmt.Errorf("no weight for multisig owner %s", owner)
+			}
+
+			w, err := strconv.Atoi(m.Weights[i])
+
+			if err != nil {
+				return 0, err
+			}
+
+			weight += w
+
+			break
+		}
+	}
+
+	return weight, nil
+}
+
+type Balance struct {
+	Coin struct {
+		ID     uint64 `json:"id,string"`
+		Symbol string `json:"symbol"`
+	} `json:"coin"`
+	Value    string `json:"value"`
+	BipValue string `json:"bip_value"`
+}
+
 type GetAddressResponse struct {
-	TransactionCount uint64 `json:"transaction_count,string"`
+	Balance          []Balance `json:"balance"`
+	TransactionCount uint64    `json:"transaction_count,string"`
+	Multisig         *Multisig `json:"multisig"`
+
+	Error *Error `json:"error"`
+}
+
+// BalanceOf returns balance of coin in pip.
+func (r *GetAddressResponse) BalanceOf(coin uint64) *big.Int {
+	for _, b := range r.Balance {
+		if b.Coin.ID != coin {
+			continue
+		}
+
+		if value, ok := new(big.Int).SetString(b.Value, 10); ok {
+			return value
+		}
+	}
+
+	return big.NewInt(0)
+}
+
+// MissedFraction returns fraction of the validator set that didn't sign the block.
+func (r *GetBlockResponse) MissedFraction() float64 {
+	if len(r.Validators) == 0 {
+		return 0
+	}
+
+	missed := 0
+
+	for _, v := range r.Validators {
+		if !v.Signed {
+			missed++
+		}
+	}
+
+	return float64(missed) / float64(len(r.Validators))
+}
+
+type EstimateCommissionResponse struct {
+	Commission string `json:"commission"`
 
 	Error *Error `json:"error"`
 }
 
+type MinGasPriceResponse struct {
+	MinGasPrice int `json:"min_gas_price,string"`
+}
+
+// CandidateOff holds everything needed to build SetCandidateOff transaction, so it can be signed
+// without requests to Node API.
+type CandidateOff struct {
+	PublicKey     string
+	WalletAddress string
+	Nonce         uint64
+	GasPrice      uint8
+	GasCoin       uint64
+	Multisig      bool
+	Balance       *big.Int
+	Commission    *big.Int
+}
+
 type SendTransactionRequest struct {
 	Tx string `json:"tx"`
 }
 
 type SendTransactionResponse struct {
-	Hash string `json:"string"`
+	Code int    `json:"code,string"`
+	Log  string `json:"log"`
+	Hash string `json:"hash"`
 
-	Error *Error `json:"error"`
+	Error    *Error `json:"error"`
+	Endpoint string `json:"-"`
+}
+
+type TransactionResponse struct {
+	Hash   string `json:"hash"`
+	Height int    `json:"height,string"`
+	From   string `json:"from"`
+	Nonce  int    `json:"nonce,string"`
+	Type   int    `json:"type,string"`
+	Code   int    `json:"code,string"`
+	Log    string `json:"log"`
+
+	Error    *Error `json:"error"`
+	Endpoint string `json:"-"`
 }
 
 type Error struct {
@@ -63,3 +191,15 @@ type Error struct {
 	Message string                  `json:"message"`
 	Data    *map[string]interface{} `json:"data"`
 }
+
+type BlockResult struct {
+	Endpoint string
+	Block    *GetBlockResponse
+	Err      error
+}
+
+type SendResult struct {
+	Endpoint string
+	Response *SendTransactionResponse
+	Err      error
+}
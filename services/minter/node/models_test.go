@@ -0,0 +1,63 @@
+package node
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMultisig_Weight(t *testing.T) {
+	multisig := &Multisig{
+		Threshold: 3,
+		Weights:   []string{"1", "2", "3"},
+		Addresses: []string{"Mx01", "Mx02", "Mx03"},
+	}
+
+	for expected, addresses := range map[int][]string{
+		0: {"Mx04"},
+		2: {"Mx02"},
+		4: {"Mx01", "Mx03", "Mx04"},
+		6: {"Mx01", "Mx02", "Mx03"},
+	} {
+		weight, err := multisig.Weight(addresses)
+
+		if err != nil {
+			t.Fatalf("failed to get weight: %s", err)
+		}
+
+		if weight != expected {
+			t.Fatalf("wrong weight of %v: expected %d, got %d", addresses, expected, weight)
+		}
+	}
+}
+
+func TestGetBlockResponse_MissedFraction(t *testing.T) {
+	var resp GetBlockResponse
+
+	if err := json.Unmarshal([]byte(`{"validators":[{"public_key":"Mp01","signed":true},{"public_key":"Mp02","signed":false},{"public_key":"Mp03","signed":false},{"public_key":"Mp04","signed":true}]}`), &resp); err != nil {
+		t.Fatalf("failed to decode response: %s", err)
+	}
+
+	if f := resp.MissedFraction(); f != 0.5 {
+		t.Fatalf("wrong missed fraction: %f", f)
+	}
+
+	if f := (&GetBlockResponse{}).MissedFraction(); f != 0 {
+		t.Fatalf("missed fraction of block without validators should be zero, got %f", f)
+	}
+}
+
+func TestGetAddressResponse_BalanceOf(t *testing.T) {
+	var resp GetAddressResponse
+
+	if err := json.Unmarshal([]byte(`{"balance":[{"coin":{"id":"0","symbol":"BIP"},"value":"1000"},{"coin":{"id":"5","symbol":"COIN"},"value":"7"}],"transaction_count":"3"}`), &resp); err != nil {
+		t.Fatalf("failed to decode response: %s", err)
+	}
+
+	if b := resp.BalanceOf(5); b.Int64() != 7 {
+		t.Fatalf("wrong balance: %s", b)
+	}
+
+	if b := resp.BalanceOf(1); b.Sign() != 0 {
+		t.Fatalf("balance of missing coin should be zero, got %s", b)
+	}
+}
@@ -3,7 +3,11 @@ package node
 import (
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/MinterTeam/minter-go-sdk/v2/transaction"
 	"github.com/MinterTeam/minter-go-sdk/v2/wallet"
@@ -12,67 +16,169 @@ import (
 )
 
 const (
-	status          = "/status"
-	candidate       = "/candidate/{candidate}"
-	getBlock        = "/block/{height}"
-	getAddress      = "/address/{address}"
-	sendTransaction = "/send_transaction"
+	status             = "/status"
+	candidate          = "/candidate/{candidate}"
+	getBlock           = "/block/{height}"
+	getAddress         = "/address/{address}"
+	sendTransaction    = "/send_transaction"
+	getTransaction     = "/transaction/{hash}"
+	minGasPrice        = "/min_gas_price"
+	estimateCommission = "/estimate_tx_commission/{tx}"
 )
 
 type Service struct {
-	nodeApis            []string
-	currentNodeApiIndex int
-	testnet             bool
-	logger              *logrus.Logger
-	http                *resty.Client
+	nodeApis         []string
+	endpoints        []*endpoint
+	testnet          bool
+	logger           *logrus.Logger
+	failureThreshold int
+	cooldown         time.Duration
+	observer         func(url string, duration time.Duration, err error)
+	gasCoin          uint64
+	gasMultiplier    float64
+	maxGasPrice      int
+	pongWait         time.Duration
 }
 
 func New(nodeApis []string, testnet bool, logger *logrus.Logger) (*Service, error) {
-	http := resty.New().
-		SetRetryCount(1).
-		SetHostURL(nodeApis[0])
+	if logger == nil {
+		logger = logrus.New()
+	}
+
+	var endpoints []*endpoint
+
+	for _, url := range nodeApis {
+		endpoints = append(endpoints, newEndpoint(url, defaultTimeout))
+	}
 
 	s := &Service{
-		nodeApis:            nodeApis,
-		currentNodeApiIndex: 0,
-		logger:              logger,
-		testnet:             testnet,
-		http:                http,
+		nodeApis:         nodeApis,
+		endpoints:        endpoints,
+		logger:           logger,
+		testnet:          testnet,
+		failureThreshold: defaultFailureThreshold,
+		cooldown:         defaultCooldown,
+		pongWait:         defaultPongWait,
 	}
 
 	return s, nil
 }
 
+func (svc *Service) SetCircuitBreaker(failureThreshold int, cooldown time.Duration) {
+	if failureThreshold > 0 {
+		svc.failureThreshold = failureThreshold
+	}
+
+	if cooldown > 0 {
+		svc.cooldown = cooldown
+	}
+}
+
+// SetTimeout sets timeout of a single request to Node API.
+func (svc *Service) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+
+	for _, e := range svc.endpoints {
+		e.http.SetTimeout(timeout)
+	}
+}
+
+// SetGas sets coin to pay commission in and gas price strategy: min gas price of the network
+// multiplied by multiplier and capped by maxGasPrice.
+func (svc *Service) SetGas(coin uint64, multiplier float64, maxGasPrice int) {
+	svc.gasCoin = coin
+	svc.gasMultiplier = multiplier
+	svc.maxGasPrice = maxGasPrice
+}
+
+func (svc *Service) SetObserver(observer func(url string, duration time.Duration, err error)) {
+	svc.observer = observer
+}
+
+func (svc *Service) Health() []EndpointHealth {
+	var health []EndpointHealth
+
+	for _, e := range svc.endpoints {
+		health = append(health, e.health())
+	}
+
+	return health
+}
+
+func (svc *Service) Endpoint() string {
+	return svc.ranked()[0].url
+}
+
 func (svc *Service) Ping() error {
-	for _, url := range svc.nodeApis {
-		svc.http.SetHostURL(url)
-		if v, err := svc.Status(); err != nil {
+	for _, e := range svc.endpoints {
+		if v, err := svc.status(e); err != nil {
 			return err
 		} else if v.CatchingUp {
-			return errors.New(fmt.Sprintf("node %s is catching up", url))
+			return errors.New(fmt.Sprintf("node %s is catching up", e.url))
 		}
 	}
 
 	return nil
 }
 
+func (svc *Service) CheckHealth() {
+	for _, e := range svc.endpoints {
+		_, _ = svc.status(e)
+	}
+}
+
 func (svc *Service) Status() (*StatusResponse, error) {
+	var res *StatusResponse
+
+	err := svc.try(func(e *endpoint) error {
+		r, err := svc.status(e)
+
+		res = r
+
+		return err
+	})
+
+	return res, err
+}
+
+func (svc *Service) status(e *endpoint) (*StatusResponse, error) {
 	var res StatusResponse
 
-	_, err := svc.http.R().
-		SetResult(&res).
-		Get(status)
+	err := svc.do(e, func() (*resty.Response, error) {
+		return e.http.R().
+			SetResult(&res).
+			Get(status)
+	})
+
+	if err == nil {
+		if res.CatchingUp {
+			err = errors.New(fmt.Sprintf("node %s is catching up", e.url))
+		} else {
+			e.setHeight(res.LatestBlockHeight)
+		}
+	}
 
 	return &res, err
 }
 
 func (svc *Service) GetCandidate(publicKey string) (*CandidateResponse, error) {
 	var res CandidateResponse
+	var r *resty.Response
 
-	r, err := svc.http.R().
-		SetPathParam("candidate", publicKey).
-		SetResult(&res).
-		Get(candidate)
+	err := svc.try(func(e *endpoint) error {
+		res = CandidateResponse{}
+
+		return svc.do(e, func() (resp *resty.Response, err error) {
+			r, err = e.http.R().
+				SetPathParam("candidate", publicKey).
+				SetResult(&res).
+				Get(candidate)
+
+			return r, err
+		})
+	})
 
 	if err != nil {
 		return nil, err
@@ -86,33 +192,85 @@ func (svc *Service) GetCandidate(publicKey string) (*CandidateResponse, error) {
 }
 
 func (svc *Service) GetBlock(height int) (*GetBlockResponse, error) {
+	var res *GetBlockResponse
+	var notFound error
+
+	err := svc.try(func(e *endpoint) (err error) {
+		res, err = svc.getBlock(e, height)
+		notFound = nil
+
+		if _, ok := err.(*BlockNotFound); ok {
+			notFound = err
+			return nil
+		}
+
+		return err
+	})
+
+	if err != nil {
+		return res, err
+	}
+
+	return res, notFound
+}
+
+// GetBlockFromEach requests the block from every endpoint concurrently.
+func (svc *Service) GetBlockFromEach(height int) []BlockResult {
+	results := make([]BlockResult, len(svc.endpoints))
+
+	var wg sync.WaitGroup
+
+	for i, e := range svc.endpoints {
+		wg.Add(1)
+
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+
+			block, err := svc.getBlock(e, height)
+
+			results[i] = BlockResult{
+				Endpoint: e.url,
+				Block:    block,
+				Err:      err,
+			}
+		}(i, e)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+func (svc *Service) getBlock(e *endpoint, height int) (*GetBlockResponse, error) {
 	var resp *resty.Response
-	var err error
-	var res GetBlockResponse
 
-	svc.try(func() error {
-		resp, err = svc.http.R().
+	res := GetBlockResponse{Endpoint: e.url}
+
+	err := svc.do(e, func() (r *resty.Response, err error) {
+		resp, err = e.http.R().
 			SetPathParam("height", strconv.Itoa(height)).
 			SetResult(&res).
 			SetError(&res).
 			Get(getBlock)
 
-		if err != nil {
-			return err
-		}
-
-		if resp.StatusCode() == 404 {
-			return nil
-		}
-
-		return err
+		return resp, err
 	})
 
+	if err != nil {
+		return &res, err
+	}
+
 	if resp.StatusCode() == 404 {
+		if res.Error == nil {
+			res.Error = &Error{Code: 404, Message: "Block not found"}
+		}
+
 		return &res, NewBlockNotFoundError(&res)
 	}
 
-	return &res, err
+	e.setHeight(height)
+
+	return &res, nil
 }
 
 func (svc *Service) Wallet(mnemonic string, seed string) (*wallet.Wallet, error) {
@@ -120,97 +278,362 @@ func (svc *Service) Wallet(mnemonic string, seed string) (*wallet.Wallet, error)
 }
 
 func (svc *Service) GenerateCandidateOffTransaction(publicKey string, walletAddress string, seeds ...string) (string, error) {
-	var chainID transaction.ChainID
-	if svc.testnet {
-		chainID = transaction.TestNetChainID
-	} else {
-		chainID = transaction.MainNetChainID
+	var privateKeys []string
+
+	for _, seed := range seeds {
+		wal, err := svc.Wallet("", seed)
+
+		if err != nil {
+			return "", err
+		}
+
+		privateKeys = append(privateKeys, wal.PrivateKey)
 	}
 
-	tx, err := transaction.NewBuilder(chainID).NewTransaction(
-		transaction.NewSetCandidateOffData().MustSetPubKey(publicKey),
-	)
+	return svc.SignCandidateOffTransaction(publicKey, walletAddress, privateKeys...)
+}
+
+// SignCandidateOffTransaction signs transaction with private keys, e.g. unlocked from keystore.
+// Transaction is signed by multisig walletAddress if it's a multisig wallet, otherwise by a single key.
+func (svc *Service) SignCandidateOffTransaction(publicKey string, walletAddress string, privateKeys ...string) (string, error) {
+	c, err := svc.NewCandidateOff(publicKey, walletAddress)
 
 	if err != nil {
 		return "", err
 	}
 
-	getAddress, err := svc.getAddress(walletAddress)
+	return svc.SignCandidateOff(c, privateKeys...)
+}
+
+// UnsignedCandidateOffTransaction builds transaction to be signed elsewhere (e.g. by remote signer).
+// Multisig address is set in signature data if walletAddress is a multisig wallet.
+func (svc *Service) UnsignedCandidateOffTransaction(publicKey string, walletAddress string) (string, error) {
+	c, err := svc.NewCandidateOff(publicKey, walletAddress)
 
 	if err != nil {
 		return "", err
 	}
 
-	tx = tx.
-		SetNonce(getAddress.TransactionCount + 1).
-		SetGasPrice(1).
-		SetGasCoin(0)
+	return svc.UnsignedCandidateOff(c)
+}
 
-	var signed transaction.Signed
+// NewCandidateOff fetches nonce and balance of walletAddress, gas price and estimated commission to build transaction.
+func (svc *Service) NewCandidateOff(publicKey string, walletAddress string) (*CandidateOff, error) {
+	address, err := svc.GetAddress(walletAddress)
 
-	if len(seeds) == 1 {
-		wal, err := svc.Wallet("", seeds[0])
+	if err != nil {
+		return nil, err
+	}
 
-		if err != nil {
-			return "", err
-		}
+	minGasPrice, err := svc.MinGasPrice()
 
-		s, err := tx.SetSignatureType(transaction.SignatureTypeSingle).Sign(wal.PrivateKey)
+	if err != nil {
+		svc.logger.WithError(err).Warnln("Failed to get min gas price. Using 1")
 
-		if err != nil {
-			return "", err
+		minGasPrice = 1
+	}
+
+	gasPrice, err := svc.gasPrice(minGasPrice)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c := &CandidateOff{
+		PublicKey:     publicKey,
+		WalletAddress: walletAddress,
+		Nonce:         address.TransactionCount + 1,
+		GasPrice:      gasPrice,
+		GasCoin:       svc.gasCoin,
+		Multisig:      address.Multisig != nil,
+		Balance:       address.BalanceOf(svc.gasCoin),
+	}
+
+	if tx, err := svc.UnsignedCandidateOff(c); err != nil {
+		return nil, err
+	} else if c.Commission, err = svc.EstimateCommission(tx); err != nil {
+		svc.logger.WithError(err).Warnln("Failed to estimate commission")
+	}
+
+	return c, nil
+}
+
+// gasPrice returns min gas price multiplied and capped as configured. Price capped below min gas price
+// is an error, because network rejects such transaction.
+func (svc *Service) gasPrice(minGasPrice uint8) (uint8, error) {
+	price := float64(minGasPrice)
+
+	if svc.gasMultiplier > 0 {
+		price = math.Ceil(price * svc.gasMultiplier)
+	}
+
+	if svc.maxGasPrice > 0 && price > float64(svc.maxGasPrice) {
+		price = float64(svc.maxGasPrice)
+	}
+
+	if price > math.MaxUint8 {
+		price = math.MaxUint8
+	}
+
+	if price < 1 {
+		price = 1
+	}
+
+	if price < float64(minGasPrice) {
+		return 0, &GasPriceBelowMin{price: uint8(price), minGasPrice: minGasPrice}
+	}
+
+	return uint8(price), nil
+}
+
+func (svc *Service) EstimateCommission(tx string) (*big.Int, error) {
+	var res EstimateCommissionResponse
+
+	err := svc.try(func(e *endpoint) error {
+		res = EstimateCommissionResponse{}
+
+		return svc.do(e, func() (*resty.Response, error) {
+			return e.http.R().
+				SetPathParam("tx", tx).
+				SetResult(&res).
+				SetError(&res).
+				Get(estimateCommission)
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Error != nil {
+		return nil, fmt.Errorf("[%d] %s", res.Error.Code, res.Error.Message)
+	}
+
+	commission, ok := new(big.Int).SetString(res.Commission, 10)
+
+	if !ok {
+		return nil, fmt.Errorf("unexpected commission %s", res.Commission)
+	}
+
+	return commission, nil
+}
+
+func (svc *Service) SignCandidateOff(c *CandidateOff, privateKeys ...string) (string, error) {
+	if len(privateKeys) == 0 {
+		return "", errors.New("no keys to sign transaction")
+	}
+
+	tx, err := svc.buildCandidateOff(c)
+
+	if err != nil {
+		return "", err
+	}
+
+	var signed transaction.Signed
+
+	if !c.Multisig {
+		if len(privateKeys) > 1 {
+			return "", fmt.Errorf("%s is not a multisig wallet, it can be signed by a single key only", c.WalletAddress)
 		}
 
-		signed = s
+		signed, err = tx.SetSignatureType(transaction.SignatureTypeSingle).Sign(privateKeys[0])
 	} else {
-		var privateKeys []string
+		signed, err = tx.SetSignatureType(transaction.SignatureTypeMulti).Sign(c.WalletAddress, privateKeys...)
+	}
 
-		for _, seed := range seeds {
-			wal, err := svc.Wallet("", seed)
+	if err != nil {
+		return "", err
+	}
 
-			if err != nil {
-				return "", err
-			}
+	return signed.Encode()
+}
 
-			privateKeys = append(privateKeys, wal.PrivateKey)
-		}
+func (svc *Service) UnsignedCandidateOff(c *CandidateOff) (string, error) {
+	tx, err := svc.buildCandidateOff(c)
 
-		s, err := tx.SetSignatureType(transaction.SignatureTypeMulti).Sign(walletAddress, privateKeys...)
+	if err != nil {
+		return "", err
+	}
 
-		if err != nil {
-			return "", err
-		}
+	if !c.Multisig {
+		return tx.SetSignatureType(transaction.SignatureTypeSingle).Encode()
+	}
+
+	signed, err := tx.SetSignatureType(transaction.SignatureTypeMulti).Sign(c.WalletAddress)
 
-		signed = s
+	if err != nil {
+		return "", err
 	}
 
 	return signed.Encode()
 }
 
+func (svc *Service) buildCandidateOff(c *CandidateOff) (transaction.Interface, error) {
+	var chainID transaction.ChainID
+	if svc.testnet {
+		chainID = transaction.TestNetChainID
+	} else {
+		chainID = transaction.MainNetChainID
+	}
+
+	data, err := transaction.NewSetCandidateOffData().SetPubKey(c.PublicKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := transaction.NewBuilder(chainID).NewTransaction(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tx.
+		SetNonce(c.Nonce).
+		SetGasPrice(c.GasPrice).
+		SetGasCoin(c.GasCoin), nil
+}
+
+func (svc *Service) MinGasPrice() (uint8, error) {
+	var res MinGasPriceResponse
+
+	err := svc.try(func(e *endpoint) error {
+		res = MinGasPriceResponse{}
+
+		return svc.do(e, func() (*resty.Response, error) {
+			return e.http.R().
+				SetResult(&res).
+				Get(minGasPrice)
+		})
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	if res.MinGasPrice < 1 || res.MinGasPrice > 255 {
+		return 0, fmt.Errorf("unexpected min gas price %d", res.MinGasPrice)
+	}
+
+	return uint8(res.MinGasPrice), nil
+}
+
 func (svc *Service) SendTransaction(tx string) (*SendTransactionResponse, error) {
-	var res SendTransactionResponse
-	var err error
+	var res *SendTransactionResponse
+
+	err := svc.try(func(e *endpoint) (err error) {
+		res, err = svc.sendTransaction(e, tx)
+
+		return err
+	})
+
+	return res, err
+}
+
+// BroadcastTransaction sends transaction through every endpoint except excluded ones.
+// If all endpoints are excluded, transaction is sent through all of them.
+func (svc *Service) BroadcastTransaction(tx string, exclude ...string) []SendResult {
+	excluded := map[string]bool{}
+
+	for _, url := range exclude {
+		excluded[url] = true
+	}
+
+	var endpoints []*endpoint
+
+	for _, e := range svc.endpoints {
+		if !excluded[e.url] {
+			endpoints = append(endpoints, e)
+		}
+	}
+
+	if len(endpoints) == 0 {
+		endpoints = svc.endpoints
+	}
+
+	results := make([]SendResult, len(endpoints))
+
+	var wg sync.WaitGroup
+
+	for i, e := range endpoints {
+		wg.Add(1)
+
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+
+			res, err := svc.sendTransaction(e, tx)
+
+			results[i] = SendResult{
+				Endpoint: e.url,
+				Response: res,
+				Err:      err,
+			}
+		}(i, e)
+	}
+
+	wg.Wait()
+
+	return results
+}
 
-	svc.try(func() error {
-		_, err := svc.http.R().
+func (svc *Service) sendTransaction(e *endpoint, tx string) (*SendTransactionResponse, error) {
+	res := SendTransactionResponse{Endpoint: e.url}
+
+	err := svc.do(e, func() (*resty.Response, error) {
+		return e.http.R().
 			SetBody(&SendTransactionRequest{Tx: tx}).
 			SetResult(&res).
 			SetError(&res).
 			Post(sendTransaction)
-
-		return err
 	})
 
 	return &res, err
 }
 
-func (svc *Service) getAddress(address string) (*GetAddressResponse, error) {
+// GetTransaction returns *TransactionNotFound error until transaction is included in a block.
+func (svc *Service) GetTransaction(hash string) (*TransactionResponse, error) {
+	var res TransactionResponse
+	var notFound error
+
+	err := svc.try(func(e *endpoint) error {
+		res = TransactionResponse{Endpoint: e.url}
+		notFound = nil
+
+		return svc.do(e, func() (*resty.Response, error) {
+			resp, err := e.http.R().
+				SetPathParam("hash", hash).
+				SetResult(&res).
+				SetError(&res).
+				Get(getTransaction)
+
+			if err == nil && resp.StatusCode() == 404 {
+				notFound = &TransactionNotFound{hash: hash}
+			}
+
+			return resp, err
+		})
+	})
+
+	if err != nil {
+		return &res, err
+	}
+
+	return &res, notFound
+}
+
+func (svc *Service) GetAddress(address string) (*GetAddressResponse, error) {
 	var res GetAddressResponse
 
-	_, err := svc.http.R().
-		SetPathParam("address", address).
-		SetResult(&res).
-		Get(getAddress)
+	err := svc.try(func(e *endpoint) error {
+		res = GetAddressResponse{}
+
+		return svc.do(e, func() (*resty.Response, error) {
+			return e.http.R().
+				SetPathParam("address", address).
+				SetResult(&res).
+				Get(getAddress)
+		})
+	})
 
 	if err != nil {
 		return &res, err
@@ -219,24 +642,43 @@ func (svc *Service) getAddress(address string) (*GetAddressResponse, error) {
 	return &res, nil
 }
 
-func (svc *Service) try(callback func() error) {
-	lastIndex := len(svc.nodeApis) - 1
+// try calls the callback against endpoints starting from the healthiest one until it succeeds.
+func (svc *Service) try(callback func(e *endpoint) error) error {
+	var err error
 
-	for i, url := range svc.nodeApis {
-		svc.http.SetHostURL(url)
+	for _, e := range svc.ranked() {
+		if err = callback(e); err == nil {
+			return nil
+		}
 
-		err := callback()
+		svc.logger.WithField("endpoint", e.url).WithError(err).Warnln("Node API request failed")
+	}
 
-		if err != nil {
-			if i == lastIndex {
-				return
-			}
+	return err
+}
 
-			continue
-		}
+// do performs a single request against the endpoint and records its outcome.
+// Transport errors and 5xx responses count as endpoint failures.
+func (svc *Service) do(e *endpoint, request func() (*resty.Response, error)) error {
+	started := time.Now()
 
-		return
+	resp, err := request()
+
+	if err == nil && resp.StatusCode() >= 500 {
+		err = errors.New(fmt.Sprintf("node %s responded with status %d", e.url, resp.StatusCode()))
+	}
+
+	duration := time.Since(started)
+
+	if opened := e.observe(duration, err, svc.failureThreshold, svc.cooldown); opened {
+		svc.logger.WithField("endpoint", e.url).WithError(err).Warnf("Node API circuit opened for %s", svc.cooldown)
+	}
+
+	svc.logger.WithField("endpoint", e.url).WithField("duration", duration).Debugln("Node API request")
+
+	if svc.observer != nil {
+		svc.observer(e.url, duration, err)
 	}
 
-	return
+	return err
 }
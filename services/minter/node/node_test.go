@@ -52,3 +52,53 @@ func TestService_GenerateCandidateOffTransaction_Multisig(t *testing.T) {
 		t.Fatalf("wrong transaction: %s", tx)
 	}
 }
+
+func TestService_SignCandidateOff(t *testing.T) {
+	svc, _ := New([]string{"http://localhost"}, true, nil)
+
+	wal1, _ := svc.Wallet("", seed1)
+	wal2, _ := svc.Wallet("", seed2)
+
+	c := &CandidateOff{PublicKey: publicKey, WalletAddress: wal1.Address, Nonce: 1, GasPrice: 1}
+
+	if _, err := svc.SignCandidateOff(c, wal1.PrivateKey); err != nil {
+		t.Fatalf("failed to sign by control address: %s", err)
+	}
+
+	if _, err := svc.SignCandidateOff(c, wal1.PrivateKey, wal2.PrivateKey); err == nil {
+		t.Fatalf("regular address should not be signed by several keys")
+	}
+
+	c.WalletAddress = multisigAddress
+	c.Multisig = true
+
+	if _, err := svc.SignCandidateOff(c, wal1.PrivateKey, wal2.PrivateKey); err != nil {
+		t.Fatalf("failed to sign by multisig owners: %s", err)
+	}
+}
+
+func TestService_GasPrice(t *testing.T) {
+	svc, _ := New([]string{"http://localhost"}, true, nil)
+
+	if price, err := svc.gasPrice(3); err != nil || price != 3 {
+		t.Fatalf("min gas price should be used by default, got %d (%v)", price, err)
+	}
+
+	svc.SetGas(0, 1.5, 0)
+
+	if price, err := svc.gasPrice(3); err != nil || price != 5 {
+		t.Fatalf("wrong multiplied gas price: expected 5, got %d (%v)", price, err)
+	}
+
+	svc.SetGas(0, 10, 20)
+
+	if price, err := svc.gasPrice(3); err != nil || price != 20 {
+		t.Fatalf("gas price should be capped: expected 20, got %d (%v)", price, err)
+	}
+
+	svc.SetGas(0, 1, 2)
+
+	if _, err := svc.gasPrice(3); err == nil {
+		t.Fatalf("expected error for gas price capped below min gas price")
+	}
+}
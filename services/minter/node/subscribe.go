@@ -0,0 +1,200 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	subscribe     = "/subscribe"
+	newBlockQuery = "tm.event = 'NewBlock'"
+
+	// Connection is dropped if nothing, not even a pong, is received within pongWait. Pings are sent twice as often.
+	defaultPongWait = 30 * time.Second
+)
+
+type NewBlockEvent struct {
+	Height int
+}
+
+type Subscription struct {
+	url      string
+	conn     *websocket.Conn
+	events   chan NewBlockEvent
+	err      error
+	pongWait time.Duration
+}
+
+type subscribeMessage struct {
+	Result *struct {
+		Query string          `json:"query"`
+		Data  json.RawMessage `json:"data"`
+	} `json:"result"`
+	Error *struct {
+		GrpcCode   int    `json:"grpc_code"`
+		HttpCode   int    `json:"http_code"`
+		Message    string `json:"message"`
+		HttpStatus string `json:"http_status"`
+	} `json:"error"`
+}
+
+type newBlockData struct {
+	Block struct {
+		Header struct {
+			Height json.Number `json:"height"`
+		} `json:"header"`
+	} `json:"block"`
+}
+
+func (svc *Service) SubscribeNewBlocks(ctx context.Context) (*Subscription, error) {
+	var lastErr error
+
+	for _, e := range svc.ranked() {
+		u, err := subscribeURL(e.url, newBlockQuery)
+
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		conn, _, err := websocket.DefaultDialer.DialContext(ctx, u, nil)
+
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		sub := &Subscription{
+			url:      u,
+			conn:     conn,
+			events:   make(chan NewBlockEvent),
+			pongWait: svc.pongWait,
+		}
+
+		go sub.read(ctx)
+
+		return sub, nil
+	}
+
+	return nil, lastErr
+}
+
+func (s *Subscription) URL() string {
+	return s.url
+}
+
+func (s *Subscription) Events() <-chan NewBlockEvent {
+	return s.events
+}
+
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+func (s *Subscription) Close() error {
+	return s.conn.Close()
+}
+
+// read sends new block events until connection is closed, fails or stops answering pings.
+func (s *Subscription) read(ctx context.Context) {
+	done := make(chan struct{})
+
+	defer close(s.events)
+	defer s.conn.Close()
+	defer close(done)
+
+	_ = s.conn.SetReadDeadline(time.Now().Add(s.pongWait))
+
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(s.pongWait))
+	})
+
+	go func() {
+		ticker := time.NewTicker(s.pongWait / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				_ = s.conn.Close()
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+				_ = s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(s.pongWait/2))
+			}
+		}
+	}()
+
+	for {
+		var msg subscribeMessage
+
+		if err := s.conn.ReadJSON(&msg); err != nil {
+			if ctx.Err() != nil {
+				s.err = ctx.Err()
+			} else {
+				s.err = err
+			}
+
+			return
+		}
+
+		_ = s.conn.SetReadDeadline(time.Now().Add(s.pongWait))
+
+		if msg.Error != nil {
+			s.err = errors.New(fmt.Sprintf("[%d] %s", msg.Error.HttpCode, msg.Error.Message))
+			return
+		}
+
+		if msg.Result == nil {
+			continue
+		}
+
+		var data newBlockData
+
+		event := NewBlockEvent{}
+
+		if err := json.Unmarshal(msg.Result.Data, &data); err == nil {
+			if height, err := strconv.Atoi(data.Block.Header.Height.String()); err == nil {
+				event.Height = height
+			}
+		}
+
+		select {
+		case s.events <- event:
+		case <-ctx.Done():
+			s.err = ctx.Err()
+			return
+		}
+	}
+}
+
+func subscribeURL(nodeApi string, query string) (string, error) {
+	u, err := url.Parse(nodeApi)
+
+	if err != nil {
+		return "", err
+	}
+
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	default:
+		return "", errors.New(fmt.Sprintf("unsupported node api scheme: %s", u.Scheme))
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + subscribe
+	u.RawQuery = url.Values{"query": []string{query}}.Encode()
+
+	return u.String(), nil
+}
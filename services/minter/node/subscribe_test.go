@@ -0,0 +1,160 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type fakeEventServer struct {
+	*httptest.Server
+
+	heights []int
+	query   chan string
+}
+
+func newFakeEventServer(heights ...int) *fakeEventServer {
+	s := &fakeEventServer{
+		heights: heights,
+		query:   make(chan string, 1),
+	}
+
+	upgrader := websocket.Upgrader{}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2"+subscribe {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		s.query <- r.URL.Query().Get("query")
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		for _, height := range s.heights {
+			msg := fmt.Sprintf(`{"result":{"query":"%s","data":{"block":{"header":{"height":"%d"}}}}}`, newBlockQuery, height)
+
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+				return
+			}
+		}
+	}))
+
+	return s
+}
+
+func TestService_SubscribeNewBlocks(t *testing.T) {
+	server := newFakeEventServer(10, 11, 12)
+	defer server.Close()
+
+	svc, _ := New([]string{server.URL + "/v2"}, true, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sub, err := svc.SubscribeNewBlocks(ctx)
+
+	if err != nil {
+		t.Fatalf("failed to subscribe: %s", err)
+	}
+
+	defer sub.Close()
+
+	if q := <-server.query; q != newBlockQuery {
+		t.Fatalf("wrong query: expected %s, got %s", newBlockQuery, q)
+	}
+
+	if !strings.HasPrefix(sub.URL(), "ws://") {
+		t.Fatalf("wrong subscription url: %s", sub.URL())
+	}
+
+	var heights []int
+
+	for event := range sub.Events() {
+		heights = append(heights, event.Height)
+	}
+
+	if len(heights) != 3 || heights[0] != 10 || heights[2] != 12 {
+		t.Fatalf("wrong heights: %v", heights)
+	}
+
+	if sub.Err() == nil {
+		t.Fatalf("expected error after stream is closed")
+	}
+}
+
+func TestService_SubscribeNewBlocks_Failover(t *testing.T) {
+	server := newFakeEventServer(5)
+	defer server.Close()
+
+	svc, _ := New([]string{server.URL + "/broken", server.URL + "/v2"}, true, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sub, err := svc.SubscribeNewBlocks(ctx)
+
+	if err != nil {
+		t.Fatalf("failed to subscribe: %s", err)
+	}
+
+	defer sub.Close()
+
+	event, ok := <-sub.Events()
+
+	if !ok || event.Height != 5 {
+		t.Fatalf("wrong event: %v", event)
+	}
+}
+
+func TestService_SubscribeNewBlocks_Stalled(t *testing.T) {
+	done := make(chan struct{})
+
+	upgrader := websocket.Upgrader{}
+
+	// Server keeps connection open without sending events and without reading, so pings are never answered.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	svc, _ := New([]string{server.URL}, true, nil)
+	svc.pongWait = 100 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sub, err := svc.SubscribeNewBlocks(ctx)
+
+	if err != nil {
+		t.Fatalf("failed to subscribe: %s", err)
+	}
+
+	for range sub.Events() {
+	}
+
+	if sub.Err() == nil || ctx.Err() != nil {
+		t.Fatalf("expected read deadline error, got %v", sub.Err())
+	}
+}
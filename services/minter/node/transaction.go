@@ -0,0 +1,124 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/MinterTeam/minter-go-sdk/v2/transaction"
+	"github.com/MinterTeam/minter-go-sdk/v2/wallet"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"golang.org/x/crypto/sha3"
+)
+
+type DecodedTransaction struct {
+	Type      transaction.Type
+	ChainID   transaction.ChainID
+	Nonce     uint64
+	GasPrice  uint8
+	GasCoin   uint64
+	PublicKey string
+	Sender    string
+	Hash      string
+}
+
+// DecodeTransaction decodes signed transaction. PublicKey is set for SetCandidateOn/SetCandidateOff transactions.
+func DecodeTransaction(tx string) (*DecodedTransaction, error) {
+	signed, err := transaction.Decode(tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	t := signed.GetTransaction()
+
+	decoded := &DecodedTransaction{
+		Type:     t.Type,
+		ChainID:  t.ChainID,
+		Nonce:    t.Nonce,
+		GasPrice: t.GasPrice,
+		GasCoin:  uint64(t.GasCoin),
+	}
+
+	switch data := signed.Data().(type) {
+	case *transaction.SetCandidateOffData:
+		decoded.PublicKey = data.PubKey.String()
+	case *transaction.SetCandidateOnData:
+		decoded.PublicKey = data.PubKey.String()
+	}
+
+	if decoded.Sender, err = sender(signed); err != nil {
+		return nil, err
+	}
+
+	if decoded.Hash, err = signed.Hash(); err != nil {
+		return nil, err
+	}
+
+	return decoded, nil
+}
+
+// sender recovers address of single signature transaction itself, as SDK recovers it from the public key
+// with the 0x04 prefix and returns wrong address.
+func sender(signed transaction.Signed) (string, error) {
+	t := signed.GetTransaction()
+
+	if t.SignatureType == transaction.SignatureTypeMulti {
+		return signed.SenderAddress()
+	}
+
+	signature, err := signed.Signature()
+
+	if err != nil {
+		return "", err
+	}
+
+	single, ok := signature.(*transaction.SignatureSingle)
+
+	if !ok || single.R == nil || single.S == nil || single.V == nil {
+		return "", errors.New("transaction is not signed")
+	}
+
+	if len(single.R.Bytes()) > 32 || len(single.S.Bytes()) > 32 {
+		return "", errors.New("malformed signature: R and S must be at most 32 bytes")
+	}
+
+	if single.V.Cmp(big.NewInt(27)) != 0 && single.V.Cmp(big.NewInt(28)) != 0 {
+		return "", fmt.Errorf("malformed signature: V is %s, expected 27 or 28", single.V)
+	}
+
+	hw := sha3.NewLegacyKeccak256()
+
+	err = rlp.Encode(hw, []interface{}{
+		t.Nonce,
+		t.ChainID,
+		t.GasPrice,
+		t.GasCoin,
+		t.Type,
+		t.Data,
+		t.Payload,
+		t.ServiceData,
+		t.SignatureType,
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	sig := make([]byte, 65)
+	copy(sig[32-len(single.R.Bytes()):32], single.R.Bytes())
+	copy(sig[64-len(single.S.Bytes()):64], single.S.Bytes())
+	sig[64] = byte(new(big.Int).Sub(single.V, big.NewInt(27)).Uint64())
+
+	publicKey, err := crypto.SigToPub(hw.Sum(nil), sig)
+
+	if err != nil {
+		return "", err
+	}
+
+	var address [20]byte
+	copy(address[:], crypto.PubkeyToAddress(*publicKey).Bytes())
+
+	return wallet.BytesToAddress(address), nil
+}
@@ -0,0 +1,93 @@
+package node
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/MinterTeam/minter-go-sdk/v2/transaction"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestDecodeTransaction(t *testing.T) {
+	svc, _ := New([]string{"http://localhost"}, true, nil)
+
+	wal, _ := svc.Wallet("", seed1)
+
+	tx, _ := transaction.NewBuilder(transaction.TestNetChainID).NewTransaction(
+		transaction.NewSetCandidateOffData().MustSetPubKey(publicKey),
+	)
+
+	tx = tx.SetNonce(7).SetGasPrice(1).SetGasCoin(0)
+
+	signed, err := tx.Clone().SetSignatureType(transaction.SignatureTypeSingle).Sign(wal.PrivateKey)
+
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %s", err)
+	}
+
+	encoded, _ := signed.Encode()
+
+	decoded, err := DecodeTransaction(encoded)
+
+	if err != nil {
+		t.Fatalf("failed to decode transaction: %s", err)
+	}
+
+	if decoded.Type != transaction.TypeSetCandidateOffline || decoded.ChainID != transaction.TestNetChainID || decoded.Nonce != 7 {
+		t.Fatalf("wrong transaction: %+v", decoded)
+	}
+
+	if decoded.PublicKey != publicKey {
+		t.Fatalf("wrong public key: expected %s, got %s", publicKey, decoded.PublicKey)
+	}
+
+	if decoded.Sender != address {
+		t.Fatalf("wrong sender: expected %s, got %s", address, decoded.Sender)
+	}
+
+	multi, err := tx.SetSignatureType(transaction.SignatureTypeMulti).Sign(multisigAddress, wal.PrivateKey)
+
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %s", err)
+	}
+
+	encoded, _ = multi.Encode()
+
+	if decoded, err := DecodeTransaction(encoded); err != nil || decoded.Sender != multisigAddress {
+		t.Fatalf("wrong multisig sender: %+v, %v", decoded, err)
+	}
+}
+
+func TestDecodeTransaction_MalformedSignature(t *testing.T) {
+	svc, _ := New([]string{"http://localhost"}, true, nil)
+
+	wal, _ := svc.Wallet("", seed1)
+
+	tx, _ := transaction.NewBuilder(transaction.TestNetChainID).NewTransaction(
+		transaction.NewSetCandidateOffData().MustSetPubKey(publicKey),
+	)
+
+	long := new(big.Int).Lsh(big.NewInt(1), 300)
+
+	for name, signature := range map[string]*transaction.SignatureSingle{
+		"long R":  {V: big.NewInt(27), R: long, S: big.NewInt(1)},
+		"long S":  {V: big.NewInt(27), R: big.NewInt(1), S: long},
+		"wrong V": {V: big.NewInt(1), R: big.NewInt(1), S: big.NewInt(1)},
+	} {
+		signed, err := tx.Clone().SetNonce(7).SetGasPrice(1).SetSignatureType(transaction.SignatureTypeSingle).Sign(wal.PrivateKey)
+
+		if err != nil {
+			t.Fatalf("failed to sign transaction: %s", err)
+		}
+
+		if signed.GetTransaction().SignatureData, err = rlp.EncodeToBytes(signature); err != nil {
+			t.Fatalf("failed to encode signature: %s", err)
+		}
+
+		encoded, _ := signed.Encode()
+
+		if _, err := DecodeTransaction(encoded); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Severity int
+
+const (
+	Info Severity = iota
+	Warning
+	Critical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Critical:
+		return "critical"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+func ParseSeverity(value string) (Severity, error) {
+	switch strings.ToLower(value) {
+	case "info":
+		return Info, nil
+	case "warning":
+		return Warning, nil
+	case "critical":
+		return Critical, nil
+	default:
+		return Info, fmt.Errorf("unknown severity: %s", value)
+	}
+}
+
+func ParseSeverities(values []string) ([]Severity, error) {
+	var severities []Severity
+
+	for _, v := range values {
+		s, err := ParseSeverity(v)
+
+		if err != nil {
+			return nil, err
+		}
+
+		severities = append(severities, s)
+	}
+
+	return severities, nil
+}
+
+type Message struct {
+	Severity  Severity
+	Text      string
+	PublicKey string
+	Time      time.Time
+	// Urgent messages are pages: they are delivered to every notifier regardless of its severities
+	Urgent bool
+}
+
+type Notifier interface {
+	Name() string
+	Notify(msg Message) error
+}
+
+type route struct {
+	notifier   Notifier
+	severities map[Severity]bool
+}
+
+// Service dispatches messages to notifiers subscribed to their severity.
+type Service struct {
+	logger *logrus.Logger
+	routes []route
+}
+
+func New(logger *logrus.Logger) *Service {
+	return &Service{logger: logger}
+}
+
+// Add registers notifier for the given severities. Notifier receives messages of every severity if none are given.
+func (s *Service) Add(n Notifier, severities ...Severity) {
+	r := route{notifier: n}
+
+	if len(severities) > 0 {
+		r.severities = map[Severity]bool{}
+
+		for _, severity := range severities {
+			r.severities[severity] = true
+		}
+	}
+
+	s.routes = append(s.routes, r)
+}
+
+func (s *Service) Len() int {
+	return len(s.routes)
+}
+
+func (s *Service) Notify(msg Message) {
+	if msg.Time.IsZero() {
+		msg.Time = time.Now()
+	}
+
+	for _, r := range s.routes {
+		if r.severities != nil && !r.severities[msg.Severity] && !msg.Urgent {
+			continue
+		}
+
+		if err := r.notifier.Notify(msg); err != nil {
+			s.logger.
+				WithField("notifier", r.notifier.Name()).
+				WithField("severity", msg.Severity).
+				WithError(err).
+				Errorln("Failed to send notification")
+		}
+	}
+}
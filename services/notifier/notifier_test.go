@@ -0,0 +1,67 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+type recorder struct {
+	messages []Message
+}
+
+func (r *recorder) Name() string {
+	return "recorder"
+}
+
+func (r *recorder) Notify(msg Message) error {
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+func TestService_Notify(t *testing.T) {
+	all := &recorder{}
+	critical := &recorder{}
+
+	svc := New(logrus.New())
+	svc.Add(all)
+	svc.Add(critical, Critical)
+
+	svc.Notify(Message{Severity: Warning, Text: "Block missed"})
+	svc.Notify(Message{Severity: Critical, Text: "Masternode is off"})
+
+	if len(all.messages) != 2 {
+		t.Fatalf("wrong number of messages: expected 2, got %d", len(all.messages))
+	}
+
+	if len(critical.messages) != 1 || critical.messages[0].Text != "Masternode is off" {
+		t.Fatalf("wrong messages: %+v", critical.messages)
+	}
+
+	if critical.messages[0].Time.IsZero() {
+		t.Fatalf("message time is not set")
+	}
+}
+
+func TestService_NotifyUrgent(t *testing.T) {
+	critical := &recorder{}
+
+	svc := New(logrus.New())
+	svc.Add(critical, Critical)
+
+	svc.Notify(Message{Severity: Warning, Text: "Block missed", Urgent: true})
+
+	if len(critical.messages) != 1 {
+		t.Fatalf("urgent message is not delivered regardless of severities: %+v", critical.messages)
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	if s, err := ParseSeverity("Warning"); err != nil || s != Warning {
+		t.Fatalf("wrong severity: %s (%v)", s, err)
+	}
+
+	if _, err := ParseSeverity("urgent"); err == nil {
+		t.Fatalf("expected error for unknown severity")
+	}
+}
@@ -0,0 +1,149 @@
+package policy
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	TypeSliding     = "sliding"
+	TypeConsecutive = "consecutive"
+	TypePercentage  = "percentage"
+	TypeMinterJail  = "minter_jail"
+
+	// Minter jails validator that missed MinterMaxAbsentTimes of the last MinterMaxAbsentWindow blocks
+	MinterMaxAbsentWindow = 24
+	MinterMaxAbsentTimes  = 12
+)
+
+// Verdict tells how many missed blocks are counted by the policy and whether masternode should be turned off.
+type Verdict struct {
+	Missed   int
+	Limit    int
+	Exceeded bool
+}
+
+// Policy decides when missed blocks are enough to turn off masternode. It gets outcome of every block in order.
+type Policy interface {
+	Name() string
+	Observe(height int, signed bool) Verdict
+	// Restore rebuilds policy from missed blocks saved before restart, when the last checked block was at height
+	Restore(missed []int, height int)
+	Reset()
+}
+
+// Sliding counts blocks missed within the last window blocks.
+type Sliding struct {
+	name      string
+	window    int
+	threshold int
+	missed    []int
+}
+
+func NewSliding(window int, threshold int) *Sliding {
+	return &Sliding{name: TypeSliding, window: window, threshold: threshold}
+}
+
+// NewMinterJail mirrors Minter jail rule and is exceeded margin blocks before the validator would be jailed.
+func NewMinterJail(margin int) (*Sliding, error) {
+	if margin < 0 || margin >= MinterMaxAbsentTimes {
+		return nil, fmt.Errorf("margin must be between 0 and %d", MinterMaxAbsentTimes-1)
+	}
+
+	return &Sliding{name: TypeMinterJail, window: MinterMaxAbsentWindow, threshold: MinterMaxAbsentTimes - margin}, nil
+}
+
+func (p *Sliding) Name() string {
+	return p.name
+}
+
+func (p *Sliding) Observe(height int, signed bool) Verdict {
+	if !signed {
+		p.missed = append(p.missed, height)
+	}
+
+	p.cleanup(height)
+
+	return Verdict{Missed: len(p.missed), Limit: p.threshold, Exceeded: len(p.missed) >= p.threshold}
+}
+
+func (p *Sliding) Restore(missed []int, height int) {
+	p.missed = append([]int(nil), missed...)
+	sort.Ints(p.missed)
+
+	p.cleanup(height)
+}
+
+func (p *Sliding) Reset() {
+	p.missed = nil
+}
+
+func (p *Sliding) cleanup(height int) {
+	var temp []int
+
+	for _, h := range p.missed {
+		if height-h < p.window {
+			temp = append(temp, h)
+		}
+	}
+
+	p.missed = temp
+}
+
+// Consecutive counts blocks missed in a row.
+type Consecutive struct {
+	threshold int
+	run       int
+}
+
+func NewConsecutive(threshold int) *Consecutive {
+	return &Consecutive{threshold: threshold}
+}
+
+func (p *Consecutive) Name() string {
+	return TypeConsecutive
+}
+
+func (p *Consecutive) Observe(height int, signed bool) Verdict {
+	if signed {
+		p.run = 0
+	} else {
+		p.run++
+	}
+
+	return Verdict{Missed: p.run, Limit: p.threshold, Exceeded: p.run >= p.threshold}
+}
+
+func (p *Consecutive) Restore(missed []int, height int) {
+	set := map[int]bool{}
+
+	for _, h := range missed {
+		set[h] = true
+	}
+
+	p.run = 0
+
+	for set[height-p.run] {
+		p.run++
+	}
+}
+
+func (p *Consecutive) Reset() {
+	p.run = 0
+}
+
+// Percentage is exceeded when percent of the last window blocks are missed.
+type Percentage struct {
+	*Sliding
+}
+
+func NewPercentage(window int, percent float64) (*Percentage, error) {
+	if percent <= 0 || percent > 100 {
+		return nil, fmt.Errorf("percent must be between 0 and 100")
+	}
+
+	threshold := int(math.Ceil(float64(window) * percent / 100))
+
+	return &Percentage{&Sliding{name: TypePercentage, window: window, threshold: threshold}}, nil
+}
@@ -0,0 +1,87 @@
+package policy
+
+import "testing"
+
+func observe(p Policy, outcomes string) Verdict {
+	var v Verdict
+
+	for i, outcome := range outcomes {
+		v = p.Observe(i+1, outcome == '+')
+	}
+
+	return v
+}
+
+func TestSliding(t *testing.T) {
+	p := NewSliding(4, 2)
+
+	if v := observe(p, "-+++-"); v.Exceeded || v.Missed != 1 {
+		t.Fatalf("missed block out of window is counted: %+v", v)
+	}
+
+	p.Reset()
+
+	if v := observe(p, "+-+-"); !v.Exceeded || v.Missed != 2 || v.Limit != 2 {
+		t.Fatalf("wrong verdict: %+v", v)
+	}
+}
+
+func TestConsecutive(t *testing.T) {
+	p := NewConsecutive(3)
+
+	if v := observe(p, "--+--"); v.Exceeded || v.Missed != 2 {
+		t.Fatalf("signed block doesn't break the run: %+v", v)
+	}
+
+	if v := p.Observe(6, false); !v.Exceeded {
+		t.Fatalf("three blocks missed in a row are not exceeded: %+v", v)
+	}
+
+	p.Restore([]int{7, 9, 10}, 10)
+
+	if v := p.Observe(11, false); !v.Exceeded || v.Missed != 3 {
+		t.Fatalf("run is not restored: %+v", v)
+	}
+}
+
+func TestPercentage(t *testing.T) {
+	p, err := NewPercentage(10, 25)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := observe(p, "+-+++-++++"); v.Exceeded || v.Limit != 3 {
+		t.Fatalf("wrong verdict: %+v", v)
+	}
+
+	if v := p.Observe(11, false); !v.Exceeded {
+		t.Fatalf("25%% of blocks missed is not exceeded: %+v", v)
+	}
+
+	if _, err := NewPercentage(10, 0); err == nil {
+		t.Fatalf("expected error for zero percent")
+	}
+}
+
+func TestMinterJail(t *testing.T) {
+	p, err := NewMinterJail(2)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.Restore([]int{2, 3, 4, 5, 6, 7, 8, 9, 10}, 24)
+
+	if v := p.Observe(25, false); !v.Exceeded || v.Limit != 10 {
+		t.Fatalf("wrong verdict: %+v", v)
+	}
+
+	if v := p.Observe(26, true); v.Exceeded || v.Missed != 9 {
+		t.Fatalf("blocks out of Minter window are counted: %+v", v)
+	}
+
+	if _, err := NewMinterJail(MinterMaxAbsentTimes); err == nil {
+		t.Fatalf("expected error for margin reaching jail limit")
+	}
+}
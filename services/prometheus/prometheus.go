@@ -2,6 +2,8 @@ package prometheus
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -12,70 +14,202 @@ import (
 type Service struct {
 	address string
 	logger  *logrus.Logger
+	mux     *http.ServeMux
 
-	blocksSigned          prometheus.Counter
-	blocksMissedTotal     prometheus.Counter
-	blocksMissedCurrent   prometheus.Gauge
-	missedBlocksThreshold prometheus.Counter
+	blocksSigned          *prometheus.CounterVec
+	blocksMissedTotal     *prometheus.CounterVec
+	blocksMissedCurrent   *prometheus.GaugeVec
+	missedBlocksThreshold *prometheus.CounterVec
 	sleep                 prometheus.Counter
+
+	nodeRequests        *prometheus.CounterVec
+	nodeRequestDuration *prometheus.HistogramVec
+	nodeEndpointUp      *prometheus.GaugeVec
+	nodeEndpointHeight  *prometheus.GaugeVec
+	nodeEndpointErrors  *prometheus.GaugeVec
+	nodeEndpointActive  *prometheus.GaugeVec
+	nodeDisagreements   *prometheus.CounterVec
+
+	presignedTransactionAge *prometheus.GaugeVec
+	controlAddressBalance   *prometheus.GaugeVec
+	turnOffCommission       *prometheus.GaugeVec
+
+	networkMissedFraction prometheus.Gauge
+	blocksExcluded        *prometheus.CounterVec
 }
 
 func New(address string, logger *logrus.Logger) (*Service, error) {
 	svc := &Service{
 		address: address,
 		logger:  logger,
+		mux:     http.NewServeMux(),
 	}
 
-	svc.missedBlocksThreshold = promauto.NewCounter(prometheus.CounterOpts{
+	svc.missedBlocksThreshold = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "minter_sentinel_missed_blocks_threshold",
 		Help: "Missed blocks threshold before masternode will go off",
-	})
+	}, []string{"public_key"})
 
 	svc.sleep = promauto.NewCounter(prometheus.CounterOpts{
 		Name: "minter_sentinel_sleep",
 		Help: "Number of seconds to sleep between checking for missed blocks",
 	})
 
-	svc.blocksSigned = promauto.NewCounter(prometheus.CounterOpts{
+	svc.blocksSigned = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "minter_sentinel_blocks_signed",
 		Help: "The total number of signed blocks",
-	})
+	}, []string{"public_key"})
 
-	svc.blocksMissedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	svc.blocksMissedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "minter_sentinel_blocks_missed_total",
 		Help: "The total number of missed blocks",
-	})
+	}, []string{"public_key"})
 
-	svc.blocksMissedCurrent = promauto.NewGauge(prometheus.GaugeOpts{
+	svc.blocksMissedCurrent = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "minter_sentinel_blocks_missed_current",
 		Help: "The current number of missed blocks",
+	}, []string{"public_key"})
+
+	svc.nodeRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "minter_sentinel_node_requests_total",
+		Help: "The total number of Node API requests",
+	}, []string{"endpoint", "result"})
+
+	svc.nodeRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "minter_sentinel_node_request_duration_seconds",
+		Help: "Node API request duration",
+	}, []string{"endpoint"})
+
+	svc.nodeEndpointUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "minter_sentinel_node_endpoint_up",
+		Help: "Whether Node API circuit is closed (1) or open (0)",
+	}, []string{"endpoint"})
+
+	svc.nodeEndpointHeight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "minter_sentinel_node_endpoint_height",
+		Help: "The latest block height seen on Node API",
+	}, []string{"endpoint"})
+
+	svc.nodeEndpointErrors = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "minter_sentinel_node_endpoint_error_rate",
+		Help: "Node API error rate (exponentially weighted)",
+	}, []string{"endpoint"})
+
+	svc.nodeEndpointActive = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "minter_sentinel_node_endpoint_active",
+		Help: "Whether Node API is currently preferred for requests",
+	}, []string{"endpoint"})
+
+	svc.nodeDisagreements = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "minter_sentinel_node_disagreements_total",
+		Help: "The total number of blocks reported missed by the primary Node API that other Node APIs reported signed",
+	}, []string{"public_key"})
+
+	svc.presignedTransactionAge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "minter_sentinel_presigned_transaction_age_seconds",
+		Help: "Age of pre-signed turn off transaction, -1 if there is none",
+	}, []string{"public_key"})
+
+	svc.controlAddressBalance = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "minter_sentinel_control_address_balance",
+		Help: "Balance of candidate control address in gas coin",
+	}, []string{"public_key", "address", "coin"})
+
+	svc.turnOffCommission = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "minter_sentinel_turn_off_commission",
+		Help: "Estimated commission of turn off transaction in gas coin",
+	}, []string{"public_key", "coin"})
+
+	svc.networkMissedFraction = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "minter_sentinel_network_missed_fraction",
+		Help: "Fraction of the validator set that missed the last checked block",
 	})
 
+	svc.blocksExcluded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "minter_sentinel_blocks_excluded_total",
+		Help: "The total number of missed blocks not counted because they were missed network-wide",
+	}, []string{"public_key"})
+
 	return svc, nil
 }
 
 func (s *Service) Start() error {
-	http.Handle("/metrics", promhttp.Handler())
+	s.mux.Handle("/metrics", promhttp.Handler())
+
+	return http.ListenAndServe(s.address, s.mux)
+}
 
-	return http.ListenAndServe(s.address, nil)
+// Handle serves additional handlers on the metrics listener.
+func (s *Service) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
 }
 
-func (s *Service) SetMissedBlocksThreshold(value int) {
-	s.missedBlocksThreshold.Add(float64(value))
+func (s *Service) SetMissedBlocksThreshold(publicKey string, value int) {
+	s.missedBlocksThreshold.WithLabelValues(publicKey).Add(float64(value))
 }
 
 func (s *Service) SetSleep(value int) {
 	s.sleep.Add(float64(value))
 }
 
-func (s *Service) BlocksSignedIncrement() {
-	s.blocksSigned.Inc()
+func (s *Service) BlocksSignedIncrement(publicKey string) {
+	s.blocksSigned.WithLabelValues(publicKey).Inc()
+}
+
+func (s *Service) BlocksMissedIncrement(publicKey string) {
+	s.blocksMissedTotal.WithLabelValues(publicKey).Inc()
+}
+
+func (s *Service) SetBlocksMissedCurrent(publicKey string, value int) {
+	s.blocksMissedCurrent.WithLabelValues(publicKey).Set(float64(value))
+}
+
+func (s *Service) ObserveNodeRequest(endpoint string, duration time.Duration, err error) {
+	result := "success"
+
+	if err != nil {
+		result = "error"
+	}
+
+	s.nodeRequests.WithLabelValues(endpoint, result).Inc()
+	s.nodeRequestDuration.WithLabelValues(endpoint).Observe(duration.Seconds())
+}
+
+func (s *Service) SetNodeEndpointHealth(endpoint string, up bool, height int, errorRate float64, active bool) {
+	s.nodeEndpointUp.WithLabelValues(endpoint).Set(boolToFloat(up))
+	s.nodeEndpointHeight.WithLabelValues(endpoint).Set(float64(height))
+	s.nodeEndpointErrors.WithLabelValues(endpoint).Set(errorRate)
+	s.nodeEndpointActive.WithLabelValues(endpoint).Set(boolToFloat(active))
+}
+
+func (s *Service) NodeDisagreementsIncrement(publicKey string) {
+	s.nodeDisagreements.WithLabelValues(publicKey).Inc()
+}
+
+func (s *Service) SetPresignedTransactionAge(publicKey string, seconds float64) {
+	s.presignedTransactionAge.WithLabelValues(publicKey).Set(seconds)
 }
 
-func (s *Service) BlocksMissedIncrement() {
-	s.blocksMissedTotal.Inc()
+func (s *Service) SetControlAddressBalance(publicKey string, address string, coin uint64, value float64) {
+	s.controlAddressBalance.WithLabelValues(publicKey, address, strconv.FormatUint(coin, 10)).Set(value)
 }
 
-func (s *Service) SetBlocksMissedCurrent(value int) {
-	s.blocksMissedCurrent.Set(float64(value))
+func (s *Service) SetTurnOffCommission(publicKey string, coin uint64, value float64) {
+	s.turnOffCommission.WithLabelValues(publicKey, strconv.FormatUint(coin, 10)).Set(value)
+}
+
+func (s *Service) SetNetworkMissedFraction(value float64) {
+	s.networkMissedFraction.Set(value)
+}
+
+func (s *Service) BlocksExcludedIncrement(publicKey string) {
+	s.blocksExcluded.WithLabelValues(publicKey).Inc()
+}
+
+func boolToFloat(v bool) float64 {
+	if v {
+		return 1
+	}
+
+	return 0
 }
@@ -0,0 +1,66 @@
+package signer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+type Client struct {
+	http *resty.Client
+}
+
+// NewClient connects to signer at unix://path or https://host:port. TLS config is required for https.
+func NewClient(url string, tlsConfig *tls.Config) (*Client, error) {
+	client := resty.New().SetTimeout(10 * time.Second)
+
+	if strings.HasPrefix(url, unixPrefix) {
+		path := strings.TrimPrefix(url, unixPrefix)
+
+		client.SetTransport(&http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+
+				return d.DialContext(ctx, "unix", path)
+			},
+		})
+
+		client.SetHostURL("http://signer")
+	} else {
+		if !strings.HasPrefix(url, "https://") || tlsConfig == nil {
+			return nil, errors.New("remote signer must be reached via unix socket or https with client certificate")
+		}
+
+		client.SetTLSClientConfig(tlsConfig)
+		client.SetHostURL(url)
+	}
+
+	return &Client{http: client}, nil
+}
+
+func (c *Client) Sign(tx string, address string) (string, error) {
+	var res Response
+
+	resp, err := c.http.R().
+		SetBody(&Request{Tx: tx, Address: address}).
+		SetResult(&res).
+		SetError(&res).
+		Post(signPath)
+
+	if err != nil {
+		return "", err
+	}
+
+	if resp.IsError() || len(res.Tx) == 0 {
+		return "", fmt.Errorf("remote signer: [%d] %s", resp.StatusCode(), res.Error)
+	}
+
+	return res.Tx, nil
+}
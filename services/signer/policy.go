@@ -0,0 +1,98 @@
+package signer
+
+import (
+	"fmt"
+
+	"github.com/MinterTeam/minter-go-sdk/v2/transaction"
+)
+
+const defaultMaxGasPrice = 10
+
+// Policy limits what signer agrees to sign: only SetCandidateOff and SetCandidateOn transactions
+// of allow-listed validators in the configured network, without payload and with bounded commission.
+type Policy struct {
+	chainID     transaction.ChainID
+	publicKeys  map[string]bool
+	gasCoins    map[transaction.CoinID]bool
+	maxGasPrice uint8
+}
+
+func NewPolicy(testnet bool, publicKeys []string) *Policy {
+	p := &Policy{
+		chainID:     transaction.MainNetChainID,
+		publicKeys:  map[string]bool{},
+		gasCoins:    map[transaction.CoinID]bool{0: true},
+		maxGasPrice: defaultMaxGasPrice,
+	}
+
+	if testnet {
+		p.chainID = transaction.TestNetChainID
+	}
+
+	for _, publicKey := range publicKeys {
+		p.publicKeys[publicKey] = true
+	}
+
+	return p
+}
+
+// SetGas sets coins allowed to pay commission in and max gas price. Zero values keep the defaults:
+// base coin and gas price of 10.
+func (p *Policy) SetGas(coins []uint64, maxGasPrice int) {
+	if len(coins) > 0 {
+		p.gasCoins = map[transaction.CoinID]bool{}
+
+		for _, coin := range coins {
+			p.gasCoins[transaction.CoinID(coin)] = true
+		}
+	}
+
+	if maxGasPrice > 255 {
+		maxGasPrice = 255
+	}
+
+	if maxGasPrice > 0 {
+		p.maxGasPrice = uint8(maxGasPrice)
+	}
+}
+
+func (p *Policy) Check(tx transaction.Signed) error {
+	t := tx.GetTransaction()
+
+	if t.ChainID != p.chainID {
+		return fmt.Errorf("%w: wrong chain id %d", NotAllowed, t.ChainID)
+	}
+
+	if len(t.Payload) > 0 {
+		return fmt.Errorf("%w: payload", NotAllowed)
+	}
+
+	if len(t.ServiceData) > 0 {
+		return fmt.Errorf("%w: service data", NotAllowed)
+	}
+
+	if t.GasPrice > p.maxGasPrice {
+		return fmt.Errorf("%w: gas price %d is above %d", NotAllowed, t.GasPrice, p.maxGasPrice)
+	}
+
+	if !p.gasCoins[t.GasCoin] {
+		return fmt.Errorf("%w: gas coin %d", NotAllowed, t.GasCoin)
+	}
+
+	var publicKey transaction.PublicKey
+
+	switch data := tx.Data().(type) {
+	case *transaction.SetCandidateOffData:
+		publicKey = data.PubKey
+	case *transaction.SetCandidateOnData:
+		publicKey = data.PubKey
+	default:
+		return fmt.Errorf("%w: transaction type %d", NotAllowed, t.Type)
+	}
+
+	if !p.publicKeys[publicKey.String()] {
+		return fmt.Errorf("%w: public key %s", NotAllowed, publicKey.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,273 @@
+package signer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"minter-sentinel/services/keystore"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/MinterTeam/minter-go-sdk/v2/transaction"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	unixPrefix = "unix://"
+	signPath   = "/sign"
+)
+
+var (
+	NotAllowed  = errors.New("transaction is not allowed by policy")
+	KeyNotFound = errors.New("no key for address")
+)
+
+type Request struct {
+	Tx      string `json:"tx"`
+	Address string `json:"address"`
+}
+
+type Response struct {
+	Tx    string `json:"tx,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type Server struct {
+	keys   []keystore.Key
+	policy *Policy
+	logger *logrus.Logger
+}
+
+func NewServer(keys []keystore.Key, policy *Policy, logger *logrus.Logger) *Server {
+	return &Server{
+		keys:   keys,
+		policy: policy,
+		logger: logger,
+	}
+}
+
+// Sign signs unsigned transaction on behalf of address. Multisig transactions are signed with all keys.
+func (s *Server) Sign(req Request) (string, error) {
+	tx, err := transaction.Decode(req.Tx)
+
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.policy.Check(tx); err != nil {
+		return "", err
+	}
+
+	var signed transaction.Signed
+
+	switch tx.GetTransaction().SignatureType {
+	case transaction.SignatureTypeSingle:
+		key, err := s.key(req.Address)
+
+		if err != nil {
+			return "", err
+		}
+
+		signed, err = tx.Sign(key.PrivateKey)
+
+		if err != nil {
+			return "", err
+		}
+	case transaction.SignatureTypeMulti:
+		signature, err := tx.Signature()
+
+		if err != nil {
+			return "", err
+		}
+
+		multi, ok := signature.(*transaction.SignatureMulti)
+
+		if !ok {
+			return "", fmt.Errorf("%w: signature of multisig transaction is not multisig", NotAllowed)
+		}
+
+		multisig := multi.Multisig
+
+		if multisig.String() != req.Address {
+			return "", fmt.Errorf("%w: multisig address %s", NotAllowed, multisig.String())
+		}
+
+		var privateKeys []string
+
+		for _, k := range s.keys {
+			privateKeys = append(privateKeys, k.PrivateKey)
+		}
+
+		signed, err = tx.Sign(req.Address, privateKeys...)
+
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", errors.New("unknown signature type")
+	}
+
+	return signed.Encode()
+}
+
+func (s *Server) key(address string) (*keystore.Key, error) {
+	for _, k := range s.keys {
+		if k.Address == address {
+			return &k, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w %s", KeyNotFound, address)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != signPath || r.Method != http.MethodPost {
+		s.write(w, http.StatusNotFound, Response{Error: "not found"})
+		return
+	}
+
+	var req Request
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		s.write(w, http.StatusBadRequest, Response{Error: err.Error()})
+		return
+	}
+
+	entry := s.logger.WithField("address", req.Address)
+
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		entry = entry.WithField("client", r.TLS.PeerCertificates[0].Subject.CommonName)
+	}
+
+	tx, err := s.Sign(req)
+
+	if errors.Is(err, NotAllowed) {
+		entry.WithError(err).Warnln("Refused to sign transaction")
+		s.write(w, http.StatusForbidden, Response{Error: err.Error()})
+		return
+	}
+
+	if err != nil {
+		entry.WithError(err).Errorln("Failed to sign transaction")
+		s.write(w, http.StatusBadRequest, Response{Error: err.Error()})
+		return
+	}
+
+	entry.Println("Transaction signed")
+
+	s.write(w, http.StatusOK, Response{Tx: tx})
+}
+
+// ListenAndServe listens on unix://path socket or on host:port with mutual TLS.
+func (s *Server) ListenAndServe(listen string, tlsConfig *tls.Config) error {
+	var listener net.Listener
+
+	if strings.HasPrefix(listen, unixPrefix) {
+		path := strings.TrimPrefix(listen, unixPrefix)
+
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		l, err := net.Listen("unix", path)
+
+		if err != nil {
+			return err
+		}
+
+		if err := os.Chmod(path, 0600); err != nil {
+			_ = l.Close()
+			return err
+		}
+
+		listener = l
+	} else {
+		if tlsConfig == nil || tlsConfig.ClientAuth != tls.RequireAndVerifyClientCert {
+			return errors.New("signer must use mutual TLS when listening on TCP")
+		}
+
+		l, err := tls.Listen("tcp", listen, tlsConfig)
+
+		if err != nil {
+			return err
+		}
+
+		listener = l
+	}
+
+	s.logger.WithField("listen", listen).Println("Signer started")
+
+	return http.Serve(listener, s)
+}
+
+func (s *Server) write(w http.ResponseWriter, code int, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		s.logger.WithError(err).Errorln("Failed to write response")
+	}
+}
+
+// ServerTLSConfig requires clients to present certificate signed by clientCA.
+func ServerTLSConfig(cert, key, clientCA string) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(cert, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := certPool(clientCA)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func ClientTLSConfig(cert, key, ca string) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(cert, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := certPool(ca)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		RootCAs:      pool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func certPool(path string) (*x509.CertPool, error) {
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+
+	return pool, nil
+}
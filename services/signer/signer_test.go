@@ -0,0 +1,195 @@
+package signer
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"minter-sentinel/services/keystore"
+
+	"github.com/MinterTeam/minter-go-sdk/v2/transaction"
+	"github.com/MinterTeam/minter-go-sdk/v2/wallet"
+	"github.com/sirupsen/logrus"
+)
+
+const publicKey = "Mp61022c1428f17e02e5b3b130564ab3d37d41ad32ba361b5704642f079888c821"
+const seed = "4518edc842a0edbf1576c69afd04e66649655c166b8805ffca9926eb942c7fc4271f766eac16887a66e302f0daa70df7893bd3fb138eab9042f1ac02d866cf3a"
+
+func newServer(t *testing.T) (*Server, keystore.Key) {
+	wal, err := wallet.Create("", seed)
+
+	if err != nil {
+		t.Fatalf("failed to create wallet: %s", err)
+	}
+
+	key := keystore.Key{Address: wal.Address, PrivateKey: wal.PrivateKey}
+
+	return NewServer([]keystore.Key{key}, NewPolicy(true, []string{publicKey}), logrus.New()), key
+}
+
+func unsigned(t *testing.T, chainID transaction.ChainID, data transaction.Data) string {
+	return unsignedWith(t, chainID, data, func(tx transaction.Interface) transaction.Interface { return tx })
+}
+
+// unsignedWith builds unsigned transaction with the given fields changed by set.
+func unsignedWith(t *testing.T, chainID transaction.ChainID, data transaction.Data, set func(transaction.Interface) transaction.Interface) string {
+	tx, err := transaction.NewBuilder(chainID).NewTransaction(data)
+
+	if err != nil {
+		t.Fatalf("failed to build transaction: %s", err)
+	}
+
+	encoded, err := set(tx.SetNonce(1).SetGasPrice(1).SetGasCoin(0).SetSignatureType(transaction.SignatureTypeSingle)).Encode()
+
+	if err != nil {
+		t.Fatalf("failed to encode transaction: %s", err)
+	}
+
+	return encoded
+}
+
+func TestServer_Sign(t *testing.T) {
+	server, key := newServer(t)
+
+	tx := unsigned(t, transaction.TestNetChainID, transaction.NewSetCandidateOffData().MustSetPubKey(publicKey))
+
+	signed, err := server.Sign(Request{Tx: tx, Address: key.Address})
+
+	if err != nil {
+		t.Fatalf("failed to sign: %s", err)
+	}
+
+	decoded, _ := transaction.Decode(tx)
+	expected, _ := decoded.Sign(key.PrivateKey)
+
+	if encoded, _ := expected.Encode(); signed != encoded {
+		t.Fatalf("wrong signed transaction: expected %s, got %s", encoded, signed)
+	}
+
+	if _, err := server.Sign(Request{
+		Tx:      unsigned(t, transaction.TestNetChainID, transaction.NewSetCandidateOffData().MustSetPubKey(publicKey)),
+		Address: "Mx0000000000000000000000000000000000000000",
+	}); !errors.Is(err, KeyNotFound) {
+		t.Fatalf("expected key not found error, got %v", err)
+	}
+}
+
+func TestPolicy_Check(t *testing.T) {
+	server, key := newServer(t)
+
+	otherPublicKey := "Mp0000000000000000000000000000000000000000000000000000000000000000"
+
+	send := transaction.NewSendData().
+		SetCoin(0).
+		SetValue(big.NewInt(1)).
+		MustSetTo(key.Address)
+
+	off := transaction.NewSetCandidateOffData().MustSetPubKey(publicKey)
+
+	for name, tx := range map[string]string{
+		"mainnet":    unsigned(t, transaction.MainNetChainID, off),
+		"public key": unsigned(t, transaction.TestNetChainID, transaction.NewSetCandidateOffData().MustSetPubKey(otherPublicKey)),
+		"send":       unsigned(t, transaction.TestNetChainID, send),
+		"payload": unsignedWith(t, transaction.TestNetChainID, off, func(tx transaction.Interface) transaction.Interface {
+			return tx.SetPayload([]byte("payload"))
+		}),
+		"service data": unsignedWith(t, transaction.TestNetChainID, off, func(tx transaction.Interface) transaction.Interface {
+			return tx.SetServiceData([]byte("data"))
+		}),
+		"gas price": unsignedWith(t, transaction.TestNetChainID, off, func(tx transaction.Interface) transaction.Interface {
+			return tx.SetGasPrice(defaultMaxGasPrice + 1)
+		}),
+		"gas coin": unsignedWith(t, transaction.TestNetChainID, off, func(tx transaction.Interface) transaction.Interface {
+			return tx.SetGasCoin(1)
+		}),
+	} {
+		if _, err := server.Sign(Request{Tx: tx, Address: key.Address}); !errors.Is(err, NotAllowed) {
+			t.Fatalf("%s: expected not allowed error, got %v", name, err)
+		}
+	}
+
+	if _, err := server.Sign(Request{
+		Tx:      unsigned(t, transaction.TestNetChainID, transaction.NewSetCandidateOnData().MustSetPubKey(publicKey)),
+		Address: key.Address,
+	}); err != nil {
+		t.Fatalf("set candidate on should be allowed: %s", err)
+	}
+}
+
+func TestPolicy_SetGas(t *testing.T) {
+	server, key := newServer(t)
+
+	server.policy.SetGas([]uint64{1}, 20)
+
+	off := transaction.NewSetCandidateOffData().MustSetPubKey(publicKey)
+
+	if _, err := server.Sign(Request{Tx: unsigned(t, transaction.TestNetChainID, off), Address: key.Address}); !errors.Is(err, NotAllowed) {
+		t.Fatalf("base coin should not be allowed, got %v", err)
+	}
+
+	tx := unsignedWith(t, transaction.TestNetChainID, off, func(tx transaction.Interface) transaction.Interface {
+		return tx.SetGasCoin(1).SetGasPrice(20)
+	})
+
+	if _, err := server.Sign(Request{Tx: tx, Address: key.Address}); err != nil {
+		t.Fatalf("configured gas coin and price should be allowed: %s", err)
+	}
+}
+
+func TestClient_Unix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "minter-sentinel")
+
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	server, key := newServer(t)
+
+	url := unixPrefix + filepath.Join(dir, "signer.sock")
+
+	go func() {
+		_ = server.ListenAndServe(url, nil)
+	}()
+
+	client, err := NewClient(url, nil)
+
+	if err != nil {
+		t.Fatalf("failed to create client: %s", err)
+	}
+
+	tx := unsigned(t, transaction.TestNetChainID, transaction.NewSetCandidateOffData().MustSetPubKey(publicKey))
+
+	var signed string
+
+	for i := 0; i < 50; i++ {
+		if signed, err = client.Sign(tx, key.Address); err == nil {
+			break
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if err != nil || len(signed) == 0 {
+		t.Fatalf("failed to sign via unix socket: %v", err)
+	}
+
+	other := unsigned(t, transaction.TestNetChainID, transaction.NewSetCandidateOnData().MustSetPubKey("Mp0000000000000000000000000000000000000000000000000000000000000000"))
+
+	if _, err := client.Sign(other, key.Address); err == nil {
+		t.Fatalf("signer should refuse transaction not allowed by policy")
+	}
+}
+
+func TestServer_RequireMutualTLS(t *testing.T) {
+	server, _ := newServer(t)
+
+	if err := server.ListenAndServe("127.0.0.1:0", nil); err == nil {
+		t.Fatalf("signer should not listen on TCP without mutual TLS")
+	}
+}
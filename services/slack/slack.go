@@ -0,0 +1,55 @@
+package slack
+
+import (
+	"errors"
+	"fmt"
+	"minter-sentinel/services/notifier"
+
+	"github.com/go-resty/resty/v2"
+)
+
+type Service struct {
+	webhookURL string
+	http       *resty.Client
+}
+
+type message struct {
+	Text string `json:"text"`
+}
+
+func New(webhookURL string) (*Service, error) {
+	if len(webhookURL) == 0 {
+		return nil, errors.New("slack webhook url is not set")
+	}
+
+	return &Service{
+		webhookURL: webhookURL,
+		http:       resty.New().SetRetryCount(2),
+	}, nil
+}
+
+func (s *Service) Name() string {
+	return "slack"
+}
+
+func (s *Service) Notify(msg notifier.Message) error {
+	text := msg.Text
+
+	if msg.Urgent {
+		text = "<!channel> " + text
+	}
+
+	resp, err := s.http.R().
+		SetBody(&message{Text: text}).
+		Post(s.webhookURL)
+
+	if err != nil {
+		return err
+	}
+
+	if resp.IsError() {
+		return fmt.Errorf("slack responded with status %d: %s", resp.StatusCode(), resp.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package state
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type State struct {
+	Testnet    bool                  `json:"testnet"`
+	Validators map[string]*Validator `json:"validators"`
+	UpdatedAt  time.Time             `json:"updated_at"`
+}
+
+type Validator struct {
+	LastBlock    int       `json:"last_block"`
+	MissedBlocks []int     `json:"missed_blocks"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type Service struct {
+	path    string
+	testnet bool
+	mu      sync.Mutex
+	state   *State
+}
+
+func New(path string, testnet bool) (*Service, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+
+	return &Service{path: path, testnet: testnet}, nil
+}
+
+func (s *Service) Path() string {
+	return s.path
+}
+
+// Load reads saved state. State saved for another network is discarded.
+func (s *Service) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state = &State{
+		Testnet:    s.testnet,
+		Validators: map[string]*Validator{},
+	}
+
+	data, err := ioutil.ReadFile(s.path)
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var state State
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	if state.Testnet != s.testnet || state.Validators == nil {
+		return nil
+	}
+
+	s.state = &state
+
+	return nil
+}
+
+// Validator returns nil if nothing has been saved for the validator yet.
+func (s *Service) Validator(publicKey string) *Validator {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == nil {
+		return nil
+	}
+
+	if v, ok := s.state.Validators[publicKey]; ok {
+		c := *v
+		c.MissedBlocks = append([]int(nil), v.MissedBlocks...)
+
+		return &c
+	}
+
+	return nil
+}
+
+func (s *Service) SaveValidator(publicKey string, validator Validator) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == nil {
+		s.state = &State{
+			Testnet:    s.testnet,
+			Validators: map[string]*Validator{},
+		}
+	}
+
+	now := time.Now()
+
+	validator.UpdatedAt = now
+	validator.MissedBlocks = append([]int(nil), validator.MissedBlocks...)
+
+	s.state.Validators[publicKey] = &validator
+	s.state.UpdatedAt = now
+
+	return s.write()
+}
+
+// write saves state to a temporary file and renames it, so the file is never left half-written.
+func (s *Service) write() error {
+	data, err := json.Marshal(s.state)
+
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
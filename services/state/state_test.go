@@ -0,0 +1,89 @@
+package state
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestService_SaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "minter-sentinel")
+
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "data", "state.json")
+
+	svc, err := New(path, true)
+
+	if err != nil {
+		t.Fatalf("failed to create state service: %s", err)
+	}
+
+	if err := svc.Load(); err != nil {
+		t.Fatalf("failed to load empty state: %s", err)
+	}
+
+	if v := svc.Validator("Mp01"); v != nil {
+		t.Fatalf("expected empty state, got %+v", v)
+	}
+
+	if err := svc.SaveValidator("Mp01", Validator{LastBlock: 100, MissedBlocks: []int{98, 99}}); err != nil {
+		t.Fatalf("failed to save state: %s", err)
+	}
+
+	if err := svc.SaveValidator("Mp02", Validator{LastBlock: 101}); err != nil {
+		t.Fatalf("failed to save state: %s", err)
+	}
+
+	svc, _ = New(path, true)
+
+	if err := svc.Load(); err != nil {
+		t.Fatalf("failed to load state: %s", err)
+	}
+
+	v := svc.Validator("Mp01")
+
+	if v == nil || v.LastBlock != 100 || len(v.MissedBlocks) != 2 || v.UpdatedAt.IsZero() {
+		t.Fatalf("wrong state: %+v", v)
+	}
+
+	if v := svc.Validator("Mp02"); v == nil || v.LastBlock != 101 {
+		t.Fatalf("wrong state: %+v", v)
+	}
+
+	files, _ := ioutil.ReadDir(filepath.Join(dir, "data"))
+
+	if len(files) != 1 {
+		t.Fatalf("temporary files left: %d files in state dir", len(files))
+	}
+}
+
+func TestService_LoadAnotherNetwork(t *testing.T) {
+	dir, err := ioutil.TempDir("", "minter-sentinel")
+
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "state.json")
+
+	testnet, _ := New(path, true)
+	_ = testnet.SaveValidator("Mp01", Validator{LastBlock: 100})
+
+	mainnet, _ := New(path, false)
+
+	if err := mainnet.Load(); err != nil {
+		t.Fatalf("failed to load state: %s", err)
+	}
+
+	if v := mainnet.Validator("Mp01"); v != nil {
+		t.Fatalf("state of another network should be discarded, got %+v", v)
+	}
+}
@@ -0,0 +1,286 @@
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"minter-sentinel/services/control"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	confirmationTimeout = 2 * time.Minute
+
+	callbackConfirmOff = "off"
+	callbackCancel     = "cancel"
+)
+
+type confirmation struct {
+	publicKey string
+	expiresAt time.Time
+}
+
+const help = `Available commands:
+/status [public key] - watcher and candidate status
+/missed [public key] - missed blocks in the current window
+/pause [public key] - suspend automatic turn off
+/resume [public key] - resume automatic turn off
+/off [public key] - turn off masternode
+
+Public key may be shortened to its prefix and is required only when several validators are watched.`
+
+// Listen handles commands sent to the bot by admins until the context is cancelled.
+func (s *Service) Listen(ctx context.Context, controller control.Controller, logger *logrus.Logger) error {
+	updates, err := s.bot.GetUpdatesChan(tgbotapi.UpdateConfig{Timeout: 60})
+
+	if err != nil {
+		return err
+	}
+
+	pending := map[string]confirmation{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.bot.StopReceivingUpdates()
+			return nil
+		case update := <-updates:
+			if update.CallbackQuery != nil {
+				s.handleCallback(update.CallbackQuery, controller, pending, logger)
+				continue
+			}
+
+			if update.Message != nil && update.Message.IsCommand() {
+				s.handleCommand(update.Message, controller, pending, logger)
+			}
+		}
+	}
+}
+
+func (s *Service) isAdmin(user *tgbotapi.User) bool {
+	if user == nil {
+		return false
+	}
+
+	for _, id := range s.admins {
+		if id == user.ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Service) reply(chatID int64, text string) {
+	_, _ = s.bot.Send(tgbotapi.NewMessage(chatID, text))
+}
+
+func (s *Service) handleCommand(msg *tgbotapi.Message, controller control.Controller, pending map[string]confirmation, logger *logrus.Logger) {
+	entry := logger.
+		WithField("telegram_user_id", msg.From.ID).
+		WithField("telegram_username", msg.From.UserName).
+		WithField("command", msg.Command())
+
+	if !s.isAdmin(msg.From) {
+		entry.Warnln("Telegram command from non-admin rejected")
+		s.reply(msg.Chat.ID, "⛔ Access denied")
+		return
+	}
+
+	entry.Println("Telegram command received")
+
+	prefix := strings.TrimSpace(msg.CommandArguments())
+	validators := control.Match(controller.Validators(), prefix)
+
+	if len(validators) == 0 && msg.Command() != "start" && msg.Command() != "help" {
+		s.reply(msg.Chat.ID, control.ValidatorNotFound.Error())
+		return
+	}
+
+	switch msg.Command() {
+	case "status":
+		var parts []string
+
+		for _, v := range validators {
+			parts = append(parts, formatStatus(v, controller))
+		}
+
+		s.reply(msg.Chat.ID, strings.Join(parts, "\n\n"))
+	case "missed":
+		var parts []string
+
+		for _, v := range validators {
+			parts = append(parts, formatMissed(v))
+		}
+
+		s.reply(msg.Chat.ID, strings.Join(parts, "\n\n"))
+	case "pause", "resume":
+		var parts []string
+
+		for _, v := range validators {
+			var err error
+
+			if msg.Command() == "pause" {
+				err = controller.Pause(v.PublicKey)
+			} else {
+				err = controller.Resume(v.PublicKey)
+			}
+
+			if err != nil {
+				parts = append(parts, fmt.Sprintf("%s: %s", v.PublicKey, err))
+			} else if msg.Command() == "pause" {
+				parts = append(parts, fmt.Sprintf("⏸ Automatic turn off is paused for %s", v.PublicKey))
+			} else {
+				parts = append(parts, fmt.Sprintf("▶️ Automatic turn off is resumed for %s", v.PublicKey))
+			}
+		}
+
+		s.reply(msg.Chat.ID, strings.Join(parts, "\n"))
+	case "off":
+		v, err := control.MatchOne(validators, "")
+
+		if err != nil {
+			s.reply(msg.Chat.ID, err.Error())
+			return
+		}
+
+		token, err := newToken()
+
+		if err != nil {
+			s.reply(msg.Chat.ID, err.Error())
+			return
+		}
+
+		for t, c := range pending {
+			if time.Now().After(c.expiresAt) {
+				delete(pending, t)
+			}
+		}
+
+		pending[token] = confirmation{publicKey: v.PublicKey, expiresAt: time.Now().Add(confirmationTimeout)}
+
+		reply := tgbotapi.NewMessage(msg.Chat.ID, fmt.Sprintf("🚨 Turn off masternode %s?", v.PublicKey))
+		reply.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("Turn off", callbackConfirmOff+":"+token),
+				tgbotapi.NewInlineKeyboardButtonData("Cancel", callbackCancel+":"+token),
+			),
+		)
+
+		_, _ = s.bot.Send(reply)
+	default:
+		s.reply(msg.Chat.ID, help)
+	}
+}
+
+func (s *Service) handleCallback(query *tgbotapi.CallbackQuery, controller control.Controller, pending map[string]confirmation, logger *logrus.Logger) {
+	entry := logger.
+		WithField("telegram_user_id", query.From.ID).
+		WithField("telegram_username", query.From.UserName).
+		WithField("callback", query.Data)
+
+	if !s.isAdmin(query.From) {
+		entry.Warnln("Telegram callback from non-admin rejected")
+		_, _ = s.bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, "Access denied"))
+		return
+	}
+
+	parts := strings.SplitN(query.Data, ":", 2)
+
+	if len(parts) != 2 {
+		return
+	}
+
+	c, ok := pending[parts[1]]
+	delete(pending, parts[1])
+
+	text := "Cancelled"
+
+	switch {
+	case parts[0] == callbackCancel:
+	case !ok || time.Now().After(c.expiresAt):
+		text = "Confirmation expired, send /off again"
+	case parts[0] == callbackConfirmOff:
+		entry.WithField("public_key", c.publicKey).Warnln("Manual turn off confirmed")
+
+		if err := controller.TurnOff(c.publicKey); err != nil {
+			text = fmt.Sprintf("Failed to turn off %s: %s", c.publicKey, err)
+		} else {
+			text = fmt.Sprintf("🚨 Turning off masternode %s", c.publicKey)
+		}
+	}
+
+	_, _ = s.bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, text))
+
+	if query.Message != nil {
+		_, _ = s.bot.Send(tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text))
+	}
+}
+
+func formatStatus(v control.Validator, controller control.Controller) string {
+	autoOff := "enabled"
+
+	if v.Paused {
+		autoOff = "paused"
+	}
+
+	if v.DryRun {
+		autoOff += " (dry run)"
+	}
+
+	lines := []string{
+		v.PublicKey,
+		fmt.Sprintf("State: %s", v.State),
+		fmt.Sprintf("Last block: %d", v.LastBlock),
+		fmt.Sprintf("Missed: %d/%d (window of %d blocks)", len(v.MissedBlocks), v.MissedBlocksThreshold, v.MissedBlockRemoveAfter),
+		fmt.Sprintf("Auto turn off: %s", autoOff),
+		fmt.Sprintf("Control address: %s", v.ControlAddress),
+	}
+
+	if c, err := controller.Candidate(v.PublicKey); err != nil {
+		lines = append(lines, fmt.Sprintf("Candidate: failed to fetch (%s)", err))
+	} else {
+		status := "offline"
+
+		if c.Status == 2 {
+			status = "online"
+		}
+
+		lines = append(lines, fmt.Sprintf("Candidate: %s, validator: %t", status, c.Validator))
+
+		if c.JailedUntil > v.LastBlock {
+			lines = append(lines, fmt.Sprintf("Jailed until: %d", c.JailedUntil))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func formatMissed(v control.Validator) string {
+	if len(v.MissedBlocks) == 0 {
+		return fmt.Sprintf("%s\nNo missed blocks in the last %d blocks", v.PublicKey, v.MissedBlockRemoveAfter)
+	}
+
+	heights := make([]string, 0, len(v.MissedBlocks))
+
+	for _, h := range v.MissedBlocks {
+		heights = append(heights, fmt.Sprintf("%d", h))
+	}
+
+	return fmt.Sprintf("%s\nMissed %d/%d: %s", v.PublicKey, len(v.MissedBlocks), v.MissedBlocksThreshold, strings.Join(heights, ", "))
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 8)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -1,7 +1,49 @@
 package telegram
 
-import tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+import (
+	"minter-sentinel/services/notifier"
 
-func New(token string) (*tgbotapi.BotAPI, error) {
-	return tgbotapi.NewBotAPI(token)
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+type Service struct {
+	bot    *tgbotapi.BotAPI
+	admins []int
+}
+
+func New(token string, admins []int) (*Service, error) {
+	bot, err := tgbotapi.NewBotAPI(token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		bot:    bot,
+		admins: admins,
+	}, nil
+}
+
+// WithAdmins returns notifier sending messages to other admins using the same bot.
+func (s *Service) WithAdmins(admins []int) *Service {
+	return &Service{
+		bot:    s.bot,
+		admins: admins,
+	}
+}
+
+func (s *Service) Name() string {
+	return "telegram"
+}
+
+func (s *Service) Notify(msg notifier.Message) error {
+	var lastErr error
+
+	for _, id := range s.admins {
+		if _, err := s.bot.Send(tgbotapi.NewMessage(int64(id), msg.Text)); err != nil {
+			lastErr = err
+		}
+	}
+
+	return lastErr
 }
@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"minter-sentinel/services/notifier"
+	"strconv"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	SignatureHeader = "X-Sentinel-Signature"
+	TimestampHeader = "X-Sentinel-Timestamp"
+)
+
+type Service struct {
+	url    string
+	secret []byte
+	http   *resty.Client
+}
+
+type Payload struct {
+	Severity  string    `json:"severity"`
+	Message   string    `json:"message"`
+	PublicKey string    `json:"public_key,omitempty"`
+	Time      time.Time `json:"time"`
+	Urgent    bool      `json:"urgent,omitempty"`
+}
+
+func New(url string, secret string) (*Service, error) {
+	if len(url) == 0 {
+		return nil, errors.New("webhook url is not set")
+	}
+
+	return &Service{
+		url:    url,
+		secret: []byte(secret),
+		http:   resty.New().SetRetryCount(2),
+	}, nil
+}
+
+func (s *Service) Name() string {
+	return "webhook"
+}
+
+func (s *Service) Notify(msg notifier.Message) error {
+	body, err := json.Marshal(&Payload{
+		Severity:  msg.Severity.String(),
+		Message:   msg.Text,
+		PublicKey: msg.PublicKey,
+		Time:      msg.Time,
+		Urgent:    msg.Urgent,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req := s.http.R().
+		SetHeader("Content-Type", "application/json").
+		SetHeader(TimestampHeader, timestamp).
+		SetBody(body)
+
+	if len(s.secret) > 0 {
+		req.SetHeader(SignatureHeader, "sha256="+Sign(s.secret, timestamp, body))
+	}
+
+	resp, err := req.Post(s.url)
+
+	if err != nil {
+		return err
+	}
+
+	if resp.IsError() {
+		return fmt.Errorf("webhook responded with status %d: %s", resp.StatusCode(), resp.String())
+	}
+
+	return nil
+}
+
+// Sign returns hex encoded HMAC-SHA256 of timestamp and body joined with a dot.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
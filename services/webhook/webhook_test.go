@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"minter-sentinel/services/notifier"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestService_Notify(t *testing.T) {
+	secret := "secret"
+
+	var payload Payload
+	var signatureValid bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		expected := "sha256=" + Sign([]byte(secret), r.Header.Get(TimestampHeader), body)
+		signatureValid = r.Header.Get(SignatureHeader) == expected
+
+		_ = json.Unmarshal(body, &payload)
+	}))
+	defer server.Close()
+
+	svc, err := New(server.URL, secret)
+
+	if err != nil {
+		t.Fatalf("failed to create webhook: %s", err)
+	}
+
+	err = svc.Notify(notifier.Message{Severity: notifier.Critical, Text: "Masternode is off", PublicKey: "Mp01"})
+
+	if err != nil {
+		t.Fatalf("failed to notify: %s", err)
+	}
+
+	if !signatureValid {
+		t.Fatalf("wrong signature")
+	}
+
+	if payload.Severity != "critical" || payload.Message != "Masternode is off" || payload.PublicKey != "Mp01" {
+		t.Fatalf("wrong payload: %+v", payload)
+	}
+}
+
+func TestService_NotifyError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	svc, _ := New(server.URL, "")
+
+	if err := svc.Notify(notifier.Message{Text: "test"}); err == nil {
+		t.Fatalf("expected error")
+	}
+}